@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// blockSize is the number of rows of a block of an archetype, a block that doubles it is split in two
+const blockSize = 512
+
+// archetype groups the Entity objects of a View that have the same set of ComponentType
+//
+// The entities of an archetype live together in a table sorted by their position in the View, so iterating trough
+// the archetypes keeps the View order. The table is split in blocks so adding an Entity only moves the rows of one
+// block, and removing an Entity leaves an empty row that is reclaimed when the block compacts
+type archetype struct {
+	view   *View                        // view that owns this archetype
+	key    string                       // key that identify this set of ComponentType
+	types  []ComponentType              // types of this archetype, sorted
+	blocks []*block                     // blocks of the table, sorted by slot, none of them is empty
+	size   int                          // number of entities in this archetype
+	edges  map[ComponentType]*archetype // archetypes that we reach adding or removing a ComponentType
+}
+
+// block is a part of the table of an archetype
+type block struct {
+	entities []*Entity // entities in this block, nil for the removed rows
+	slots    []int     // slot of the Entity in each row, sorted
+	holes    int       // number of removed rows
+}
+
+// cursor is a position in the table of an archetype
+type cursor struct {
+	block int // block of the position
+	row   int // row in the block
+}
+
+// has check that the archetype has the given ComponentType
+func (arch archetype) has(ctype ComponentType) bool {
+	i := sort.Search(len(arch.types), func(i int) bool {
+		return arch.types[i] >= ctype
+	})
+	return i < len(arch.types) && arch.types[i] == ctype
+}
+
+// find returns the first block with entities in the given slot or after it, the number of blocks if there is none
+func (arch archetype) find(slot int) int {
+	return sort.Search(len(arch.blocks), func(i int) bool {
+		b := arch.blocks[i]
+		return b.slots[len(b.slots)-1] >= slot
+	})
+}
+
+// seek returns the position of the first Entity in the archetype with a slot bigger than the given one
+func (arch archetype) seek(slot int) cursor {
+	c := cursor{block: arch.find(slot + 1)}
+	if c.block < len(arch.blocks) {
+		c.row = sort.SearchInts(arch.blocks[c.block].slots, slot+1)
+	}
+	return c
+}
+
+// skip moves a cursor to the next row with an Entity, returning false if there is none
+func (arch archetype) skip(c *cursor) bool {
+	for ; c.block < len(arch.blocks); c.block, c.row = c.block+1, 0 {
+		b := arch.blocks[c.block]
+		for ; c.row < len(b.entities); c.row++ {
+			if b.entities[c.row] != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// at returns the Entity in a cursor
+func (arch archetype) at(c cursor) *Entity {
+	return arch.blocks[c.block].entities[c.row]
+}
+
+// each calls a function for every Entity in the archetype
+func (arch archetype) each(fn func(ent *Entity)) {
+	for _, b := range arch.blocks {
+		for _, ent := range b.entities {
+			if ent != nil {
+				fn(ent)
+			}
+		}
+	}
+}
+
+// insert an Entity in the archetype keeping the entities sorted by slot
+func (arch *archetype) insert(ent *Entity) {
+	i := arch.find(ent.slot)
+	if i == len(arch.blocks) {
+		// after all the entities, use the last block unless is full
+		if i > 0 && len(arch.blocks[i-1].entities) < blockSize {
+			i--
+		} else {
+			arch.blocks = append(arch.blocks, &block{})
+		}
+	}
+	b := arch.blocks[i]
+	b.insert(ent)
+	ent.arch = arch
+	arch.size++
+	if len(b.entities) >= 2*blockSize {
+		arch.split(i)
+	}
+}
+
+// remove an Entity from the archetype
+func (arch *archetype) remove(ent *Entity) {
+	b := ent.block
+	b.entities[ent.row] = nil
+	b.holes++
+	if b.holes == len(b.entities) {
+		arch.drop(b)
+	} else if b.holes > len(b.entities)/2 {
+		b.compact()
+	}
+	ent.arch = nil
+	ent.block = nil
+	arch.size--
+}
+
+// drop an empty block from the archetype
+func (arch *archetype) drop(b *block) {
+	for i := arch.find(b.slots[len(b.slots)-1]); i < len(arch.blocks); i++ {
+		if arch.blocks[i] == b {
+			copy(arch.blocks[i:], arch.blocks[i+1:])
+			arch.blocks[len(arch.blocks)-1] = nil
+			arch.blocks = arch.blocks[:len(arch.blocks)-1]
+			return
+		}
+	}
+}
+
+// split a block in two halves
+func (arch *archetype) split(i int) {
+	b := arch.blocks[i]
+	b.compact()
+	half := len(b.entities) / 2
+	other := &block{
+		entities: append(make([]*Entity, 0, blockSize), b.entities[half:]...),
+		slots:    append(make([]int, 0, blockSize), b.slots[half:]...),
+	}
+	for r, ent := range other.entities {
+		ent.block = other
+		ent.row = r
+	}
+	b.truncate(half)
+
+	arch.blocks = append(arch.blocks, nil)
+	copy(arch.blocks[i+2:], arch.blocks[i+1:])
+	arch.blocks[i+1] = other
+}
+
+// sort the entities in the archetype by slot
+func (arch *archetype) sort() {
+	entities := make([]*Entity, 0, arch.size)
+	arch.each(func(ent *Entity) {
+		entities = append(entities, ent)
+	})
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].slot < entities[j].slot
+	})
+	arch.clear()
+	for _, ent := range entities {
+		arch.insert(ent)
+	}
+}
+
+// clear removes all the entities from the archetype
+func (arch *archetype) clear() {
+	for i := range arch.blocks {
+		arch.blocks[i] = nil
+	}
+	arch.blocks = arch.blocks[:0]
+	arch.size = 0
+}
+
+// insert an Entity in the block keeping the entities sorted by slot
+func (b *block) insert(ent *Entity) {
+	r := sort.SearchInts(b.slots, ent.slot)
+	switch {
+	case r < len(b.entities) && b.entities[r] == nil:
+		// reuse the removed row in our place
+		b.holes--
+	case r > 0 && b.entities[r-1] == nil:
+		// reuse the removed row before us
+		r--
+		b.holes--
+	default:
+		b.entities = append(b.entities, nil)
+		b.slots = append(b.slots, 0)
+		copy(b.entities[r+1:], b.entities[r:])
+		copy(b.slots[r+1:], b.slots[r:])
+		for i := r + 1; i < len(b.entities); i++ {
+			if moved := b.entities[i]; moved != nil {
+				moved.row = i
+			}
+		}
+	}
+	b.entities[r] = ent
+	b.slots[r] = ent.slot
+	ent.block = b
+	ent.row = r
+}
+
+// compact remove the empty rows from the block keeping the order of the entities
+func (b *block) compact() {
+	r := 0
+	for i, ent := range b.entities {
+		if ent != nil {
+			b.entities[r] = ent
+			b.slots[r] = b.slots[i]
+			ent.row = r
+			r++
+		}
+	}
+	b.truncate(r)
+	b.holes = 0
+}
+
+// truncate the block to the given number of rows
+func (b *block) truncate(rows int) {
+	for i := rows; i < len(b.entities); i++ {
+		b.entities[i] = nil
+	}
+	b.entities = b.entities[:rows]
+	b.slots = b.slots[:rows]
+}
+
+// archetypeKey returns the key for a sorted set of ComponentType
+func archetypeKey(types []ComponentType) string {
+	var sb strings.Builder
+	for i, t := range types {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(t), 10))
+	}
+	return sb.String()
+}
+
+// newArchetype creates a new archetype for a View with a sorted set of ComponentType
+func newArchetype(view *View, types []ComponentType) *archetype {
+	return &archetype{
+		view:   view,
+		key:    archetypeKey(types),
+		types:  types,
+		blocks: make([]*block, 0),
+		edges:  make(map[ComponentType]*archetype),
+	}
+}
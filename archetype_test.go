@@ -0,0 +1,283 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func expectArchetypes(t *testing.T, view *View, want map[string]int) {
+	t.Helper()
+	got := make(map[string]int)
+	for _, arch := range view.archetypes {
+		if arch.size > 0 {
+			got[arch.key] = arch.size
+		}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("error on archetypes got %v, want %v", got, want)
+	}
+}
+
+func TestView_Archetypes(t *testing.T) {
+	view := NewView(10)
+
+	pos := archetypeKey([]ComponentType{positionCompType})
+	vel := archetypeKey([]ComponentType{velocityCompType})
+	both := archetypeKey([]ComponentType{velocityCompType, positionCompType})
+
+	id1 := view.AddEntity(positionComp{}, velocityComp{})
+	id2 := view.AddEntity(positionComp{})
+	view.AddEntity(velocityComp{}, positionComp{})
+
+	expectArchetypes(t, view, map[string]int{both: 2, pos: 1})
+
+	ent, _ := view.Get(id2)
+	ent.Add(velocityComp{})
+	expectArchetypes(t, view, map[string]int{both: 3})
+
+	ent, _ = view.Get(id1)
+	ent.Remove(positionCompType)
+	expectArchetypes(t, view, map[string]int{both: 2, vel: 1})
+
+	_ = view.Remove(id1)
+	expectArchetypes(t, view, map[string]int{both: 2})
+
+	view.Clear()
+	expectArchetypes(t, view, map[string]int{})
+}
+
+func TestIterator_Archetypes(t *testing.T) {
+	view := NewView(10)
+
+	view.AddEntity(positionComp{})
+	view.AddEntity(velocityComp{})
+	view.AddEntity(positionComp{}, velocityComp{})
+
+	it := view.Iterator(velocityCompType)
+
+	got := len(it.archetypes)
+	want := 2
+
+	if got != want {
+		t.Fatalf("error on iterator archetypes got %d, want %d", got, want)
+	}
+}
+
+func TestArchetype_Edges(t *testing.T) {
+	view := NewView(10)
+	root := view.archetype([]ComponentType{})
+
+	pos := view.edge(root, positionCompType)
+	both := view.edge(pos, velocityCompType)
+
+	if got := view.edge(both, velocityCompType); got != pos {
+		t.Fatalf("error on edge got %v, want %v", got.key, pos.key)
+	}
+
+	if got := view.edge(pos, positionCompType); got != root {
+		t.Fatalf("error on edge got %v, want %v", got.key, root.key)
+	}
+
+	if got := view.edge(view.edge(root, velocityCompType), positionCompType); got != both {
+		t.Fatalf("error on edge got %v, want %v", got.key, both.key)
+	}
+}
+
+func TestView_Records(t *testing.T) {
+	view := NewView(2)
+
+	for i := 0; i < 100; i++ {
+		id := view.AddEntity(positionComp{})
+		if i%2 == 0 {
+			_ = view.Remove(id)
+		}
+	}
+
+	// removed indexes are reused, so we only need records for the entities alive at the same time
+	got := len(view.records) - 1
+	want := view.Size()
+
+	if got != want {
+		t.Fatalf("error on records size got %d, want %d", got, want)
+	}
+
+	view.Clear()
+
+	if got = len(view.freeIDs); got != want {
+		t.Fatalf("error on free ids after clear got %d, want %d", got, want)
+	}
+}
+
+func TestQuery_Archetypes(t *testing.T) {
+	view := NewView(10)
+
+	view.AddEntity(positionComp{})
+	q := view.Query(With(velocityCompType))
+
+	if got := len(q.archetypes); got != 0 {
+		t.Fatalf("error on query archetypes got %d, want 0", got)
+	}
+
+	view.AddEntity(velocityComp{})
+	view.AddEntity(positionComp{}, velocityComp{})
+	view.AddEntity(positionComp{})
+
+	if got := len(q.archetypes); got != 2 {
+		t.Fatalf("error on query archetypes got %d, want 2", got)
+	}
+}
+
+func expectRows(t *testing.T, arch *archetype) {
+	t.Helper()
+	size, last := 0, -1
+	for _, b := range arch.blocks {
+		holes := 0
+		for r, ent := range b.entities {
+			if ent == nil {
+				holes++
+				continue
+			}
+			if ent.block != b || ent.row != r || ent.arch != arch {
+				t.Fatalf("error on row got %d, want %d", ent.row, r)
+			}
+			if ent.slot != b.slots[r] || ent.slot <= last {
+				t.Fatalf("error on slot got %d, after %d", ent.slot, last)
+			}
+			last = ent.slot
+			size++
+		}
+		if holes != b.holes || holes == len(b.entities) || holes > len(b.entities)/2 {
+			t.Fatalf("error on holes got %d, for %d rows", holes, len(b.entities))
+		}
+	}
+	if size != arch.size {
+		t.Fatalf("error on size got %d, want %d", arch.size, size)
+	}
+}
+
+func TestArchetype_Remove(t *testing.T) {
+	view := NewView(10)
+
+	for i := 0; i < 10; i++ {
+		view.AddEntity(positionComp{x: float32(i)})
+	}
+	arch := view.archetype([]ComponentType{positionCompType})
+
+	// remove while iterating
+	got := make([]float32, 0)
+	for it := view.Iterator(); it != nil; it = it.Next() {
+		ent := it.Value()
+		got = append(got, ent.Get(positionCompType).(positionComp).x)
+		if len(got)%3 != 0 {
+			_ = view.Remove(ent.ID())
+		}
+		expectRows(t, arch)
+	}
+
+	want := []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("error on iterate got %v, want %v", got, want)
+	}
+
+	got = got[:0]
+	for it := view.Iterator(); it != nil; it = it.Next() {
+		got = append(got, it.Value().Get(positionCompType).(positionComp).x)
+	}
+
+	want = []float32{2, 5, 8}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("error on iterate after remove got %v, want %v", got, want)
+	}
+}
+
+func TestView_FreeSlots(t *testing.T) {
+	view := NewView(4)
+	view.AddEntity(positionComp{})
+	id2 := view.AddEntity(positionComp{})
+	view.AddEntity(positionComp{})
+	id4 := view.AddEntity(positionComp{})
+
+	_ = view.Remove(id2)
+	_ = view.Remove(id4)
+
+	// the last freed slot is the first to be reused
+	id5 := view.AddEntity(positionComp{})
+	id6 := view.AddEntity(positionComp{})
+
+	for _, tt := range []struct {
+		id   EntityID
+		slot int
+	}{{id5, 3}, {id6, 1}} {
+		if got, _ := view.locate(tt.id); got != tt.slot {
+			t.Fatalf("error on slot got %d, want %d", got, tt.slot)
+		}
+	}
+}
+
+func TestArchetype_Blocks(t *testing.T) {
+	view := NewView(10)
+
+	ids := make([]EntityID, 0)
+	for i := 0; i < 5*blockSize; i++ {
+		ids = append(ids, view.AddEntity(positionComp{x: float32(i)}))
+	}
+	pos := view.archetype([]ComponentType{positionCompType})
+	both := view.archetype([]ComponentType{velocityCompType, positionCompType})
+
+	for i, id := range ids {
+		ent, _ := view.Get(id)
+		switch i % 3 {
+		case 0:
+			ent.Add(velocityComp{})
+		case 1:
+			_ = view.Remove(id)
+			ids[i] = view.AddEntity(positionComp{x: float32(i)})
+		}
+	}
+	expectRows(t, pos)
+	expectRows(t, both)
+
+	if len(pos.blocks) < 2 || len(both.blocks) < 2 {
+		t.Fatalf("error on blocks got %d and %d, want more than one", len(pos.blocks), len(both.blocks))
+	}
+
+	// entities are in View order
+	last := -1
+	for it := view.Iterator(positionCompType); it != nil; it = it.Next() {
+		if slot := it.Value().slot; slot <= last {
+			t.Fatalf("error on iterate got slot %d after %d", slot, last)
+		} else {
+			last = slot
+		}
+	}
+
+	for _, id := range ids {
+		_ = view.Remove(id)
+	}
+	if len(pos.blocks) != 0 || len(both.blocks) != 0 {
+		t.Fatalf("error on blocks got %d and %d, want 0", len(pos.blocks), len(both.blocks))
+	}
+}
@@ -22,6 +22,12 @@
 
 package goecs
 
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
 // ComponentType represents the type of a Component
 type ComponentType uint64
 
@@ -35,6 +41,38 @@ var globalType ComponentType = 0
 
 // NewComponentType return a new component type
 func NewComponentType() ComponentType {
-	globalType++
-	return globalType
+	return ComponentType(atomic.AddUint64((*uint64)(&globalType), 1))
+}
+
+var (
+	registryMutex sync.RWMutex                           // registryMutex protects the registry
+	registry      = make(map[reflect.Type]ComponentType) // registry of ComponentType for go types
+	componentType = reflect.TypeOf((*Component)(nil)).Elem()
+)
+
+// TypeOf returns the ComponentType for a go type
+//
+// If the type implements Component it will return the value of Type(), otherwise a new ComponentType will be
+// registered for that type the first time that is requested
+func TypeOf[T any]() ComponentType {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if rt.Kind() != reflect.Pointer && rt.Kind() != reflect.Interface && rt.Implements(componentType) {
+		var zero T
+		return any(zero).(Component).Type()
+	}
+
+	registryMutex.RLock()
+	ctype, ok := registry[rt]
+	registryMutex.RUnlock()
+
+	if !ok {
+		registryMutex.Lock()
+		if ctype, ok = registry[rt]; !ok {
+			ctype = NewComponentType()
+			registry[rt] = ctype
+		}
+		registryMutex.Unlock()
+	}
+
+	return ctype
 }
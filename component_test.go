@@ -61,3 +61,34 @@ func TestNewComponentType(t *testing.T) {
 		t.Fatalf("vel and pos have the same type")
 	}
 }
+
+type healthComp struct {
+	points int
+}
+
+type nameComp string
+
+func TestTypeOf(t *testing.T) {
+	if got := TypeOf[velocityComp](); got != velocityCompType {
+		t.Fatalf("error on type of a Component got %v, want %v", got, velocityCompType)
+	}
+
+	health := TypeOf[healthComp]()
+	name := TypeOf[nameComp]()
+
+	if health == name {
+		t.Fatalf("health and name have the same type")
+	}
+
+	if health == velocityCompType || health == positionCompType {
+		t.Fatalf("health has the same type than a Component")
+	}
+
+	if got := TypeOf[healthComp](); got != health {
+		t.Fatalf("error on type of registered type got %v, want %v", got, health)
+	}
+
+	if got := TypeOf[*healthComp](); got == health {
+		t.Fatalf("pointer and value have the same type")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import "fmt"
+
+// RunCondition is a named predicate that should be true for a System to run on Update
+type RunCondition struct {
+	Name  string                  // Name of the condition, reported in SystemStatus when the System is skipped
+	Check func(world *World) bool // Check returns true if the System should run
+}
+
+// RunIf returns a SystemOption to run a System only if all the given varg RunCondition are true
+func RunIf(conditions ...RunCondition) SystemOption {
+	return func(sr *systemRegistration) {
+		sr.conditions = append(sr.conditions, conditions...)
+	}
+}
+
+// ResourceIs returns a RunCondition that is true when the resource with the given EntityID has a component of type
+// T equal to a value
+//
+//	world.AddSystem(GameplaySystem, goecs.RunIf(goecs.ResourceIs(stateID, GameState{Playing: true})))
+func ResourceIs[T comparable](id EntityID, value T) RunCondition {
+	return RunCondition{
+		Name: fmt.Sprintf("resource %d is %v", id, value),
+		Check: func(world *World) bool {
+			res, err := world.GetResource(id)
+			if err != nil {
+				return false
+			}
+			current, ok := Get[T](res)
+			return ok && current == value
+		},
+	}
+}
+
+// EveryNFrames returns a RunCondition that is true once every n frames, starting on the first one, see World.Frame
+func EveryNFrames(n uint64) RunCondition {
+	return RunCondition{
+		Name: fmt.Sprintf("every %d frames", n),
+		Check: func(world *World) bool {
+			return n > 0 && world.Frame()%n == 0
+		},
+	}
+}
+
+// SignalPending returns a RunCondition that is true when a signal of the given ComponentType has been sent in the
+// current frame, with World.Signal or World.Emit, by the systems that already run, their listeners or before the
+// Update
+func SignalPending(signal ComponentType) RunCondition {
+	return RunCondition{
+		Name: fmt.Sprintf("signal %d sent", signal),
+		Check: func(world *World) bool {
+			return world.subscriptions.wasSent(signal)
+		},
+	}
+}
+
+// skipped returns the name of the first RunCondition of a registration that is false, empty if all are true
+func (sr *systemRegistration) skipped(world *World) string {
+	for _, condition := range sr.conditions {
+		if !condition.Check(world) {
+			return condition.Name
+		}
+	}
+	return ""
+}
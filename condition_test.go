@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"github.com/juan-medina/goecs"
+	"reflect"
+	"testing"
+)
+
+var gameStateType = goecs.NewComponentType()
+
+type gameState struct {
+	Playing bool
+}
+
+func (g gameState) Type() goecs.ComponentType {
+	return gameStateType
+}
+
+func TestWorld_RunIf(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	stateID := world.AddResource(gameState{Playing: false})
+
+	idA := world.AddSystem(systemA, goecs.RunIf(goecs.ResourceIs(stateID, gameState{Playing: true})))
+	idB := world.AddSystem(systemB, goecs.RunIf(goecs.EveryNFrames(2)))
+	world.AddSystem(func(world *goecs.World, _ float32) error {
+		if world.Frame() == 1 {
+			world.Signal(dummySignal{})
+		}
+		return nil
+	})
+	world.AddSystem(systemC, goecs.RunIf(goecs.SignalPending(dummySignalType)))
+
+	_ = world.Update(0)
+
+	status, _ := world.SystemStatus(idA)
+	if want := "resource 1 is {true}"; status.Skipped != want {
+		t.Fatalf("got skipped %q, want %q", status.Skipped, want)
+	}
+
+	res, _ := world.GetResource(stateID)
+	res.Set(gameState{Playing: true})
+
+	_ = world.Update(0)
+
+	status, _ = world.SystemStatus(idA)
+	if status.Skipped != "" {
+		t.Fatalf("got skipped %q, want empty", status.Skipped)
+	}
+
+	status, _ = world.SystemStatus(idB)
+	if want := "every 2 frames"; status.Skipped != want {
+		t.Fatalf("got skipped %q, want %q", status.Skipped, want)
+	}
+
+	_ = world.Update(0)
+
+	expect := []string{"update b", "update a", "update c", "update a", "update b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	if world.Frame() != 3 {
+		t.Fatalf("got frame %d, want 3", world.Frame())
+	}
+}
+
+func TestWorld_RunIfSignalSent(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	world.AddListener(func(world *goecs.World, _ goecs.Component, _ float32) error {
+		world.Signal(resetSignalEvent{})
+		return nil
+	}, dummySignalType)
+
+	world.AddSystem(func(world *goecs.World, _ float32) error {
+		if world.Frame() == 0 {
+			return world.Emit(dummySignal{})
+		}
+		return nil
+	})
+	world.AddSystem(systemA, goecs.RunIf(goecs.SignalPending(dummySignalType)))
+	world.AddSystem(systemB, goecs.RunIf(goecs.SignalPending(resetSignalEventType)))
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if err := world.Update(0); err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+
+	expect := []string{"update a", "update b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
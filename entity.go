@@ -28,12 +28,45 @@ import (
 )
 
 // EntityID is the ID for an Entity
+//
+// The lower 32 bits are the index of the Entity and the higher 32 bits are the generation of that index, when an
+// Entity is removed it index could be reused with a new generation, so EntityID of removed Entity are not valid
 type EntityID uint64
 
+// Index returns the index part of the EntityID
+func (id EntityID) Index() uint32 {
+	return uint32(id)
+}
+
+// Generation returns the generation part of the EntityID
+func (id EntityID) Generation() uint32 {
+	return uint32(id >> 32)
+}
+
+// newEntityID creates an EntityID from an index and a generation
+func newEntityID(index, generation uint32) EntityID {
+	return EntityID(generation)<<32 | EntityID(index)
+}
+
+// componentData holds the value of a component and the ticks when it was added and changed
+//
+// Entity keeps a pointer to it so setting a component that already exists does not modify the components map and
+// systems running in parallel could set different components of the same Entity
+type componentData struct {
+	value   interface{} // value of the component
+	added   uint64      // tick when the component was added
+	changed uint64      // tick when the component was last set
+}
+
 // Entity represents a instance of an object in a ECS
 type Entity struct {
 	id         EntityID
-	components map[ComponentType]Component
+	components map[ComponentType]*componentData
+	removed    map[ComponentType]uint64 // tick when a ComponentType was removed
+	arch       *archetype               // archetype of this Entity if it belongs to a View
+	slot       int                      // position of this Entity in the View
+	block      *block                   // block of the archetype that has this Entity
+	row        int                      // position of this Entity in it block
 }
 
 // ID : get the unique id for this Entity
@@ -49,7 +82,7 @@ func (ent Entity) String() string {
 		if result != "" {
 			result += ","
 		}
-		result += fmt.Sprintf("%s%v", reflect.TypeOf(v), v)
+		result += fmt.Sprintf("%s%v", reflect.TypeOf(v.value), v.value)
 	}
 
 	return "Entity{" + result + "}"
@@ -59,7 +92,8 @@ func (ent Entity) String() string {
 func NewEntity(ID EntityID, components ...Component) *Entity {
 	ent := Entity{
 		id:         ID,
-		components: make(map[ComponentType]Component),
+		components: make(map[ComponentType]*componentData),
+		removed:    make(map[ComponentType]uint64),
 	}
 
 	for _, v := range components {
@@ -70,23 +104,67 @@ func NewEntity(ID EntityID, components ...Component) *Entity {
 
 // Add a new component into an Entity
 func (ent *Entity) Add(component Component) *Entity {
-	ent.components[component.Type()] = component
+	return ent.add(component.Type(), component)
+}
+
+// add a value for the given ComponentType into an Entity
+func (ent *Entity) add(ctype ComponentType, value interface{}) *Entity {
+	tick := ent.tick()
+	data, exists := ent.components[ctype]
+	if !exists {
+		data = &componentData{added: tick}
+		ent.components[ctype] = data
+		delete(ent.removed, ctype)
+	}
+	data.value = value
+	data.changed = tick
+	if !exists && ent.arch != nil {
+		ent.arch.view.migrate(ent, ctype)
+		ent.arch.view.added(ent, ctype)
+	}
 	return ent
 }
 
+// tick returns the current change tick of the View of this Entity, 0 if it does not belong to a View
+func (ent Entity) tick() uint64 {
+	if ent.arch != nil {
+		return ent.arch.view.tick
+	}
+	return 0
+}
+
 // Set a new component into an Entity
 func (ent *Entity) Set(component Component) *Entity {
 	return ent.Add(component)
 }
 
-// Get the component of the given ComponentType
+// Get the component of the given ComponentType, nil if the Entity does not have it or it was set with Set[T] and
+// does not implement Component, use Get[T] for those
 func (ent Entity) Get(ctype ComponentType) Component {
-	return ent.components[ctype]
+	if component, ok := ent.value(ctype).(Component); ok {
+		return component
+	}
+	return nil
+}
+
+// value returns the value of the component of the given ComponentType, nil if the Entity does not have it
+func (ent Entity) value(ctype ComponentType) interface{} {
+	if data, exists := ent.components[ctype]; exists {
+		return data.value
+	}
+	return nil
 }
 
 // Remove the component of the given ComponentType
 func (ent *Entity) Remove(ctype ComponentType) {
-	delete(ent.components, ctype)
+	if data, exists := ent.components[ctype]; exists {
+		delete(ent.components, ctype)
+		ent.removed[ctype] = ent.tick()
+		if ent.arch != nil {
+			ent.arch.view.migrate(ent, ctype)
+			ent.arch.view.removed(ent, ctype, data.value)
+		}
+	}
 }
 
 // Contains check that the Entity has the given varg ComponentType
@@ -117,9 +195,13 @@ func (ent Entity) NotContains(types ...ComponentType) bool {
 	return noContains
 }
 
-// Clear the Entity
+// Clear the Entity, if the Entity belongs to a View it will be removed from it
 func (ent *Entity) Clear() {
-	ent.components = make(map[ComponentType]Component)
+	if ent.arch != nil {
+		ent.arch.view.release(ent)
+	}
+	ent.components = make(map[ComponentType]*componentData)
+	ent.removed = make(map[ComponentType]uint64)
 	ent.id = 0
 }
 
@@ -128,11 +210,40 @@ func (ent Entity) IsEmpty() bool {
 	return len(ent.components) == 0
 }
 
-// Reuse this Entity with new data
+// Reuse this Entity with new data, if the Entity belongs to a View it stays on it with the same EntityID and only
+// the components are replaced
 func (ent *Entity) Reuse(id EntityID, components ...Component) {
-	ent.Clear()
-	ent.id = id
+	if ent.arch != nil {
+		for ctype := range ent.components {
+			ent.Remove(ctype)
+		}
+	} else {
+		ent.Clear()
+		ent.id = id
+	}
 	for _, v := range components {
 		ent.Add(v)
 	}
 }
+
+// Get the component of type T from an Entity, ok will be false if the Entity does not have it
+func Get[T any](ent *Entity) (value T, ok bool) {
+	value, ok = ent.value(TypeOf[T]()).(T)
+	return
+}
+
+// Set a component of type T into an Entity, T does not need to implement Component
+func Set[T any](ent *Entity, value T) *Entity {
+	return ent.add(TypeOf[T](), value)
+}
+
+// Has check that the Entity has a component of type T
+func Has[T any](ent *Entity) bool {
+	_, ok := ent.components[TypeOf[T]()]
+	return ok
+}
+
+// Remove the component of type T from an Entity
+func Remove[T any](ent *Entity) {
+	ent.Remove(TypeOf[T]())
+}
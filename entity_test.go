@@ -364,3 +364,95 @@ func TestEntity_Reuse(t *testing.T) {
 		t.Fatalf("error on Reuse, expect to not contains vel but contains it")
 	}
 }
+
+func TestEntity_ReuseInView(t *testing.T) {
+	view := goecs.NewView(2)
+	id := view.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+
+	ent, _ := view.Get(id)
+	ent.Reuse(5, Pos{X: 2, Y: 2})
+
+	// the Entity stays in the View with the same EntityID
+	if !view.Has(id) || view.Size() != 1 || ent.ID() != id {
+		t.Fatalf("error on reuse, expect entity %d in the view got %v", id, view)
+	}
+
+	if got := ent.Get(PosType).(Pos); got != (Pos{X: 2, Y: 2}) || ent.Contains(VelType) {
+		t.Fatalf("error on reuse got %v, want only %v", ent, Pos{X: 2, Y: 2})
+	}
+
+	if first, err := view.First(PosType); err != nil || first != id {
+		t.Fatalf("error on first got %v, %v, want %v", first, err, id)
+	}
+}
+
+type Health struct {
+	Points int
+}
+
+func TestGenerics(t *testing.T) {
+	ent := goecs.NewEntity(1, Pos{X: 1, Y: 1})
+
+	goecs.Set(ent, Health{Points: 10})
+	goecs.Set(ent, Vel{X: 2, Y: 2})
+
+	if !goecs.Has[Health](ent) || !goecs.Has[Vel](ent) || !goecs.Has[Pos](ent) {
+		t.Fatalf("error on has, expect to have Health, Vel and Pos")
+	}
+
+	health, ok := goecs.Get[Health](ent)
+	if !ok || health.Points != 10 {
+		t.Fatalf("error on get Health got %v, %v", health, ok)
+	}
+
+	// Component types are accessible with both apis
+	pos, ok := goecs.Get[Pos](ent)
+	if !ok || pos != ent.Get(PosType).(Pos) {
+		t.Fatalf("error on get Pos got %v, %v", pos, ok)
+	}
+
+	if !ent.Contains(VelType, goecs.TypeOf[Health]()) {
+		t.Fatalf("error on contains, expect to contain Vel and Health")
+	}
+
+	// Get returns a Component, so types that are not one are only accessible with Get[T]
+	var component goecs.Component = ent.Get(VelType)
+	if component == nil || component.Type() != VelType {
+		t.Fatalf("error on get Vel got %v", component)
+	}
+
+	if got := ent.Get(goecs.TypeOf[Health]()); got != nil {
+		t.Fatalf("error on get Health got %v, want nil", got)
+	}
+
+	goecs.Remove[Health](ent)
+
+	if goecs.Has[Health](ent) {
+		t.Fatalf("error on has, expect to not have Health")
+	}
+
+	health, ok = goecs.Get[Health](ent)
+	if ok || health != (Health{}) {
+		t.Fatalf("error on get removed Health got %v, %v", health, ok)
+	}
+}
+
+func TestGenerics_View(t *testing.T) {
+	view := goecs.NewView(2)
+
+	id := view.AddEntity(Pos{X: 1, Y: 1})
+	view.AddEntity(Pos{X: 2, Y: 2})
+
+	ent, _ := view.Get(id)
+	goecs.Set(ent, Health{Points: 5})
+
+	got := make([]goecs.EntityID, 0)
+	for it := view.Iterator(goecs.TypeOf[Health]()); it != nil; it = it.Next() {
+		got = append(got, it.Value().ID())
+	}
+
+	expect := []goecs.EntityID{id}
+	if !entitiesEqual(got, expect) {
+		t.Fatalf("error on iterator got %v, want %v", got, expect)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorPolicy defines what happens when a System or a Listener returns an error
+type ErrorPolicy int
+
+// Error policies
+const (
+	FailFast ErrorPolicy = iota // FailFast stops on the first error and returns it, this is the default
+	Continue                    // Continue runs the rest of systems and listeners and returns all the errors joined
+)
+
+// collect adds an error to a list of errors, returning true if the policy should stop on it
+func (policy ErrorPolicy) collect(errs *[]error, err error) bool {
+	if err == nil {
+		return false
+	}
+	*errs = append(*errs, err)
+	return policy == FailFast
+}
+
+// joinErrors returns nil if there is no errors, the error if there is only one, or all of them joined
+func joinErrors(errs ...error) error {
+	var err error
+	for _, e := range errs {
+		if e == nil {
+			continue
+		}
+		if err != nil {
+			return errors.Join(errs...)
+		}
+		err = e
+	}
+	return err
+}
+
+// ErrorHandler handles the error of a System, returning the error to report or nil if it has been handled
+type ErrorHandler func(world *World, err error) error
+
+// OnError returns a SystemOption to handle the errors of a System, the error that the handler gets is a *SystemError
+//
+// Systems running in parallel could invoke their handlers at the same time
+func OnError(handler ErrorHandler) SystemOption {
+	return func(sr *systemRegistration) {
+		sr.handler = handler
+	}
+}
+
+// SystemError is the error returned by a System
+type SystemError struct {
+	ID   SystemID // ID of the System
+	Name string   // Name of the System
+	Err  error    // Err is the error returned by the System
+}
+
+// Error returns the error message
+func (e *SystemError) Error() string {
+	return fmt.Sprintf("system %s: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the error returned by the System
+func (e *SystemError) Unwrap() error {
+	return e.Err
+}
+
+// ListenerError is the error returned by a Listener for a signal
+type ListenerError struct {
+	Name   string    // Name of the Listener
+	Signal Component // Signal that the Listener was notified
+	Err    error     // Err is the error returned by the Listener
+}
+
+// Error returns the error message
+func (e *ListenerError) Error() string {
+	return fmt.Sprintf("listener %s on signal %T: %v", e.Name, e.Signal, e.Err)
+}
+
+// Unwrap returns the error returned by the Listener
+func (e *ListenerError) Unwrap() error {
+	return e.Err
+}
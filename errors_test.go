@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"errors"
+	"github.com/juan-medina/goecs"
+	"reflect"
+	"testing"
+)
+
+func TestWorld_ErrorPolicyFailFast(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	id := world.AddSystem(FailureSystem)
+	world.AddSystem(systemA)
+	world.AddListener(FailureListener, dummySignalType)
+	world.AddListener(listenerA, dummySignalType)
+
+	err := world.Update(0)
+
+	var systemErr *goecs.SystemError
+	if !errors.As(err, &systemErr) || systemErr.ID != id || !errors.Is(err, errFailure) {
+		t.Fatalf("error on update got %v, want system error", err)
+	}
+	if want := "system github.com/juan-medina/goecs_test.FailureSystem: failure"; err.Error() != want {
+		t.Fatalf("error on update got %q, want %q", err.Error(), want)
+	}
+
+	world.Signal(dummySignal{})
+	world.Signal(dummySignal{})
+	_ = world.DisableSystem(id)
+
+	err = world.Update(0)
+
+	var listenerErr *goecs.ListenerError
+	if !errors.As(err, &listenerErr) || listenerErr.Signal != (dummySignal{}) || !errors.Is(err, errFailure) {
+		t.Fatalf("error on update got %v, want listener error", err)
+	}
+
+	// pending signals are discarded
+	_ = world.EnableSystem(id)
+	_ = world.RemoveSystem(id)
+	_ = world.Update(0)
+
+	expect := []string{"update a", "update a"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_ErrorPolicyContinue(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+	world.SetErrorPolicy(goecs.Continue)
+
+	world.AddSystem(FailureSystem)
+	world.AddSystem(systemA)
+	world.AddSystem(FailureSystem)
+	world.AddListener(FailureListener, dummySignalType)
+	world.AddListener(listenerA, dummySignalType)
+
+	world.Signal(dummySignal{})
+	err := world.Update(0)
+
+	if !errors.Is(err, errFailure) {
+		t.Fatalf("error on update got %v, want %v", err, errFailure)
+	}
+
+	var multi interface{ Unwrap() []error }
+	if !errors.As(err, &multi) {
+		t.Fatalf("error on update got %v, want joined errors", err)
+	}
+
+	expect := []string{"update a", "notify a"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_OnError(t *testing.T) {
+	world := goecs.Default()
+
+	var handled *goecs.SystemError
+	world.AddSystem(FailureSystem, goecs.OnError(func(_ *goecs.World, err error) error {
+		errors.As(err, &handled)
+		return nil
+	}))
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	if handled == nil || handled.Name != "github.com/juan-medina/goecs_test.FailureSystem" {
+		t.Fatalf("got handled %v, want FailureSystem error", handled)
+	}
+}
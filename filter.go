@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+// Filter select the Entity objects that an Iterator or a query will return
+type Filter struct {
+	with     []ComponentType   // the Entity should have all of them
+	without  []ComponentType   // the Entity should not have any of them
+	anyOf    [][]ComponentType // the Entity should have at least one of each group
+	optional []ComponentType   // the Entity may have them
+	added    []ComponentType   // the Entity should have added them since the System last run
+	changed  []ComponentType   // the Entity should have changed them since the System last run
+	removed  []ComponentType   // the Entity should have removed them since the System last run
+}
+
+// Term is a condition to be added to a Filter
+type Term func(filter *Filter)
+
+// With returns a Term for entities that has all the given varg ComponentType
+func With(types ...ComponentType) Term {
+	return func(filter *Filter) {
+		filter.with = append(filter.with, types...)
+	}
+}
+
+// Without returns a Term for entities that has none of the given varg ComponentType
+func Without(types ...ComponentType) Term {
+	return func(filter *Filter) {
+		filter.without = append(filter.without, types...)
+	}
+}
+
+// AnyOf returns a Term for entities that has at least one of the given varg ComponentType
+func AnyOf(types ...ComponentType) Term {
+	return func(filter *Filter) {
+		filter.anyOf = append(filter.anyOf, types)
+	}
+}
+
+// Optional returns a Term for ComponentType that the entities may have
+//
+// Optional types are not required even if they are in other Term, so a typed query could use it for a component that
+// the entities may not have, returning the zero value when they do not have it
+func Optional(types ...ComponentType) Term {
+	return func(filter *Filter) {
+		filter.optional = append(filter.optional, types...)
+	}
+}
+
+// Added returns a Term for entities that has added the given varg ComponentType since the System last run
+//
+// When used outside of a System all the entities that has the ComponentType are returned
+func Added(types ...ComponentType) Term {
+	return func(filter *Filter) {
+		filter.with = append(filter.with, types...)
+		filter.added = append(filter.added, types...)
+	}
+}
+
+// Changed returns a Term for entities that has set the given varg ComponentType since the System last run, adding a
+// component also counts as a change
+//
+// When used outside of a System all the entities that has the ComponentType are returned
+func Changed(types ...ComponentType) Term {
+	return func(filter *Filter) {
+		filter.with = append(filter.with, types...)
+		filter.changed = append(filter.changed, types...)
+	}
+}
+
+// Removed returns a Term for entities that has removed the given varg ComponentType since the System last run and
+// has not added them again
+//
+// When used outside of a System all the entities that has ever removed the ComponentType are returned
+func Removed(types ...ComponentType) Term {
+	return func(filter *Filter) {
+		filter.without = append(filter.without, types...)
+		filter.removed = append(filter.removed, types...)
+	}
+}
+
+// NewFilter creates a Filter with the given varg Term
+func NewFilter(terms ...Term) Filter {
+	filter := Filter{}
+	for _, term := range terms {
+		term(&filter)
+	}
+
+	// optional types are not required
+	if len(filter.optional) > 0 {
+		with := make([]ComponentType, 0, len(filter.with))
+		for _, t := range filter.with {
+			if !containsType(filter.optional, t) {
+				with = append(with, t)
+			}
+		}
+		filter.with = with
+	}
+
+	return filter
+}
+
+// matches check that an archetype match the Filter, entities without components never match
+func (f Filter) matches(arch *archetype) bool {
+	if len(arch.types) == 0 {
+		return false
+	}
+
+	for _, t := range f.with {
+		if !arch.has(t) {
+			return false
+		}
+	}
+
+	for _, t := range f.without {
+		if arch.has(t) {
+			return false
+		}
+	}
+
+	for _, group := range f.anyOf {
+		found := false
+		for _, t := range group {
+			if arch.has(t) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tracks check if the Filter need to check the changes of each Entity
+func (f Filter) tracks() bool {
+	return len(f.added) > 0 || len(f.changed) > 0 || len(f.removed) > 0
+}
+
+// accepts check that an Entity of a matching archetype has the changes that the Filter requires after a given tick
+func (f Filter) accepts(ent *Entity, since uint64) bool {
+	// the types may be Optional, so the Entity could not have them
+	for _, t := range f.added {
+		if data, ok := ent.components[t]; !ok || data.added <= since {
+			return false
+		}
+	}
+
+	for _, t := range f.changed {
+		if data, ok := ent.components[t]; !ok || data.changed <= since {
+			return false
+		}
+	}
+
+	for _, t := range f.removed {
+		if tick, ok := ent.removed[t]; !ok || tick <= since {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsType check if a ComponentType is in a slice
+func containsType(types []ComponentType, ctype ComponentType) bool {
+	for _, t := range types {
+		if t == ctype {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"github.com/juan-medina/goecs"
+	"testing"
+)
+
+var FrozenType = goecs.TypeOf[Frozen]()
+
+func TestView_Filter(t *testing.T) {
+	view := goecs.NewView(10)
+
+	id1 := view.AddEntity(Pos{X: 1, Y: 1}, Vel{X: 1, Y: 1})
+	id2 := view.AddEntity(Pos{X: 2, Y: 2}, Vel{X: 1, Y: 1})
+	id3 := view.AddEntity(Pos{X: 3, Y: 3})
+	id4 := view.AddEntity(Vel{X: 4, Y: 4})
+
+	ent, _ := view.Get(id2)
+	goecs.Set(ent, Frozen{})
+
+	type testCase struct {
+		name   string
+		terms  []goecs.Term
+		expect []goecs.EntityID
+	}
+	var cases = []testCase{
+		{
+			name:   "with pos and vel",
+			terms:  []goecs.Term{goecs.With(PosType, VelType)},
+			expect: []goecs.EntityID{id1, id2},
+		},
+		{
+			name:   "with pos and vel without frozen",
+			terms:  []goecs.Term{goecs.With(PosType, VelType), goecs.Without(FrozenType)},
+			expect: []goecs.EntityID{id1},
+		},
+		{
+			name:   "any of pos or vel",
+			terms:  []goecs.Term{goecs.AnyOf(PosType, VelType)},
+			expect: []goecs.EntityID{id1, id2, id3, id4},
+		},
+		{
+			name:   "any of pos or vel, without vel",
+			terms:  []goecs.Term{goecs.AnyOf(PosType, VelType), goecs.Without(VelType)},
+			expect: []goecs.EntityID{id3},
+		},
+		{
+			name:   "with pos, optional vel",
+			terms:  []goecs.Term{goecs.With(PosType, VelType), goecs.Optional(VelType)},
+			expect: []goecs.EntityID{id1, id2, id3},
+		},
+		{
+			name:   "without terms",
+			terms:  []goecs.Term{},
+			expect: []goecs.EntityID{id1, id2, id3, id4},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result := make([]goecs.EntityID, 0)
+			for it := view.Filter(tt.terms...); it != nil; it = it.Next() {
+				result = append(result, it.Value().ID())
+			}
+
+			if !entitiesEqual(result, tt.expect) {
+				t.Fatalf("error on filter got %v, want %v", result, tt.expect)
+			}
+		})
+	}
+}
+
+func TestFilter_ChangesOptional(t *testing.T) {
+	view := goecs.NewView(10)
+
+	view.AddEntity(Pos{X: 1, Y: 1})
+	id2 := view.AddEntity(Pos{X: 2, Y: 2}, Vel{X: 1, Y: 1})
+
+	for _, term := range []goecs.Term{goecs.Added(VelType), goecs.Changed(VelType)} {
+		result := make([]goecs.EntityID, 0)
+		for it := view.Filter(goecs.With(PosType), term, goecs.Optional(VelType)); it != nil; it = it.Next() {
+			result = append(result, it.Value().ID())
+		}
+
+		if expect := []goecs.EntityID{id2}; !entitiesEqual(result, expect) {
+			t.Fatalf("error on filter got %v, want %v", result, expect)
+		}
+	}
+}
+
+func TestFilter_Changes(t *testing.T) {
+	world := goecs.Default()
+
+	id1 := world.AddEntity(Pos{X: 1, Y: 1})
+	id2 := world.AddEntity(Pos{X: 2, Y: 2}, Vel{X: 1, Y: 1})
+
+	var added, changed, removed []goecs.EntityID
+
+	collect := func(terms ...goecs.Term) []goecs.EntityID {
+		result := make([]goecs.EntityID, 0)
+		for it := world.Filter(terms...); it != nil; it = it.Next() {
+			result = append(result, it.Value().ID())
+		}
+		return result
+	}
+
+	changedPos := world.Query(goecs.Changed(PosType))
+	world.AddSystemWithPriority(HMovementSystem, 100)
+	world.AddSystem(func(world *goecs.World, _ float32) error {
+		added = collect(goecs.Added(PosType))
+		removed = collect(goecs.Removed(VelType))
+		changed = make([]goecs.EntityID, 0)
+		for it := changedPos.Iterator(); it != nil; it = it.Next() {
+			changed = append(changed, it.Value().ID())
+		}
+		return nil
+	})
+
+	type frame struct {
+		name    string
+		setup   func()
+		added   []goecs.EntityID
+		changed []goecs.EntityID
+		removed []goecs.EntityID
+	}
+	for _, f := range []frame{
+		{
+			name:    "first update report all added",
+			setup:   func() {},
+			added:   []goecs.EntityID{id1, id2},
+			changed: []goecs.EntityID{id1, id2},
+			removed: []goecs.EntityID{},
+		},
+		{
+			name:    "second update report the moved entity",
+			setup:   func() {},
+			added:   []goecs.EntityID{},
+			changed: []goecs.EntityID{id2},
+			removed: []goecs.EntityID{},
+		},
+		{
+			name: "changes between updates are reported",
+			setup: func() {
+				ent, _ := world.Get(id2)
+				ent.Remove(VelType)
+				ent, _ = world.Get(id1)
+				ent.Set(Pos{X: 5, Y: 5})
+			},
+			added:   []goecs.EntityID{},
+			changed: []goecs.EntityID{id1},
+			removed: []goecs.EntityID{id2},
+		},
+		{
+			name:    "no changes",
+			setup:   func() {},
+			added:   []goecs.EntityID{},
+			changed: []goecs.EntityID{},
+			removed: []goecs.EntityID{},
+		},
+	} {
+		f.setup()
+		if err := world.Update(0); err != nil {
+			t.Fatalf("%s: error on update got %v, want nil", f.name, err)
+		}
+		if !entitiesEqual(added, f.added) {
+			t.Fatalf("%s: error on added got %v, want %v", f.name, added, f.added)
+		}
+		if !entitiesEqual(changed, f.changed) {
+			t.Fatalf("%s: error on changed got %v, want %v", f.name, changed, f.changed)
+		}
+		if !entitiesEqual(removed, f.removed) {
+			t.Fatalf("%s: error on removed got %v, want %v", f.name, removed, f.removed)
+		}
+	}
+
+	// outside of a system all the changes are reported
+	if got := changedPos.Size(); got != 2 {
+		t.Fatalf("error on changed size outside a system got %d, want 2", got)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import "math"
+
+// SetFixedTimestep enables the fixed timestep for the systems in FixedUpdateStage, see World.SetFixedTimestep
+func (sys *Systems) SetFixedTimestep(step float32, maxSubSteps int) {
+	if maxSubSteps < 1 {
+		maxSubSteps = 1
+	}
+	sys.step = step
+	sys.maxSubSteps = maxSubSteps
+	sys.accumulator = 0
+}
+
+// Alpha returns how far we are between the last fixed step and the next one, from 0 to 1, is 1 if the fixed timestep
+// is not enabled
+func (sys Systems) Alpha() float32 {
+	if sys.step <= 0 {
+		return 1
+	}
+	return sys.accumulator / sys.step
+}
+
+// fixedSteps accumulates a delta and returns how many fixed steps should run
+func (sys *Systems) fixedSteps(delta float32) int {
+	sys.accumulator += delta
+	steps := 0
+	for sys.accumulator >= sys.step && steps < sys.maxSubSteps {
+		sys.accumulator -= sys.step
+		steps++
+	}
+	// discard the steps that we could not run
+	if sys.accumulator >= sys.step {
+		sys.accumulator = float32(math.Mod(float64(sys.accumulator), float64(sys.step)))
+	}
+	return steps
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"github.com/juan-medina/goecs"
+	"reflect"
+	"testing"
+)
+
+func TestWorld_SetFixedTimestep(t *testing.T) {
+	var fixed, frames []float32
+	world := goecs.Default()
+
+	world.AddSystem(func(_ *goecs.World, delta float32) error {
+		fixed = append(fixed, delta)
+		return nil
+	}, goecs.InStage(goecs.FixedUpdateStage))
+	world.AddSystem(func(_ *goecs.World, delta float32) error {
+		frames = append(frames, delta)
+		return nil
+	})
+
+	// without fixed timestep, fixed systems run once per frame
+	_ = world.Update(0.3)
+	if want := []float32{0.3}; !reflect.DeepEqual(fixed, want) {
+		t.Fatalf("got %v, want %v", fixed, want)
+	}
+	if world.Alpha() != 1 {
+		t.Fatalf("got alpha %v, want 1", world.Alpha())
+	}
+
+	world.SetFixedTimestep(0.25, 3)
+
+	var tests = []struct {
+		name  string
+		delta float32
+		steps int
+		alpha float32
+	}{
+		{name: "less than a step", delta: 0.125, steps: 0, alpha: 0.5},
+		{name: "accumulated step", delta: 0.125, steps: 1, alpha: 0},
+		{name: "many steps", delta: 0.5625, steps: 2, alpha: 0.25},
+		{name: "max sub steps", delta: 2, steps: 3, alpha: 0.25},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			fixed, frames = nil, nil
+			if err := world.Update(tc.delta); err != nil {
+				t.Fatalf("error on update got %v, want nil", err)
+			}
+			if len(fixed) != tc.steps {
+				t.Fatalf("got %d steps, want %d", len(fixed), tc.steps)
+			}
+			for _, delta := range fixed {
+				if delta != 0.25 {
+					t.Fatalf("got fixed delta %v, want 0.25", delta)
+				}
+			}
+			if want := []float32{tc.delta}; !reflect.DeepEqual(frames, want) {
+				t.Fatalf("got %v, want %v", frames, want)
+			}
+			if world.Alpha() != tc.alpha {
+				t.Fatalf("got alpha %v, want %v", world.Alpha(), tc.alpha)
+			}
+		})
+	}
+}
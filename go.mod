@@ -1,3 +1,3 @@
 module github.com/juan-medina/goecs
 
-go 1.15
+go 1.20
@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+// Types of the lifecycle signals
+var (
+	EntityCreatedType    = NewComponentType() // EntityCreatedType is the ComponentType of EntityCreated
+	EntityRemovedType    = NewComponentType() // EntityRemovedType is the ComponentType of EntityRemoved
+	ComponentAddedType   = NewComponentType() // ComponentAddedType is the ComponentType of ComponentAdded
+	ComponentRemovedType = NewComponentType() // ComponentRemovedType is the ComponentType of ComponentRemoved
+)
+
+// EntityCreated is the signal sent when an Entity is added to the World
+type EntityCreated struct {
+	ID EntityID // ID of the Entity
+}
+
+// Type will return EntityCreated ComponentType
+func (ec EntityCreated) Type() ComponentType {
+	return EntityCreatedType
+}
+
+// EntityRemoved is the signal sent when an Entity is removed from the World
+type EntityRemoved struct {
+	ID EntityID // ID of the Entity
+}
+
+// Type will return EntityRemoved ComponentType
+func (er EntityRemoved) Type() ComponentType {
+	return EntityRemovedType
+}
+
+// ComponentAdded is the signal sent when a component is added to an Entity of the World, including the components
+// of new entities
+type ComponentAdded struct {
+	ID            EntityID      // ID of the Entity
+	ComponentType ComponentType // ComponentType of the added component
+}
+
+// Type will return ComponentAdded ComponentType
+func (ca ComponentAdded) Type() ComponentType {
+	return ComponentAddedType
+}
+
+// ComponentRemoved is the signal sent when a component is removed from an Entity of the World, including the
+// components of removed entities
+type ComponentRemoved struct {
+	ID            EntityID      // ID of the Entity
+	ComponentType ComponentType // ComponentType of the removed component
+	Value         interface{}   // Value of the removed component
+}
+
+// Type will return ComponentRemoved ComponentType
+func (cr ComponentRemoved) Type() ComponentType {
+	return ComponentRemovedType
+}
+
+// lifecycle receives the lifecycle signals of a View
+type lifecycle interface {
+	// listening check if there is any Listener for the given signal type
+	listening(signal ComponentType) bool
+	// Signal to be sent
+	Signal(signal interface{})
+}
+
+// listening check if the lifecycle of the View is listening to the given signal type
+func (v *View) listening(signal ComponentType) bool {
+	return v.lifecycle != nil && v.lifecycle.listening(signal)
+}
+
+// created sends the lifecycle signals for a new Entity
+func (v *View) created(ent *Entity) {
+	if v.listening(EntityCreatedType) {
+		v.lifecycle.Signal(EntityCreated{ID: ent.id})
+	}
+	if v.listening(ComponentAddedType) {
+		for _, t := range ent.arch.types {
+			v.lifecycle.Signal(ComponentAdded{ID: ent.id, ComponentType: t})
+		}
+	}
+}
+
+// added sends the lifecycle signal for a component added to an Entity
+func (v *View) added(ent *Entity, ctype ComponentType) {
+	if v.listening(ComponentAddedType) {
+		v.lifecycle.Signal(ComponentAdded{ID: ent.id, ComponentType: ctype})
+	}
+}
+
+// removed sends the lifecycle signal for a component removed from an Entity
+func (v *View) removed(ent *Entity, ctype ComponentType, value interface{}) {
+	if v.listening(ComponentRemovedType) {
+		v.lifecycle.Signal(ComponentRemoved{ID: ent.id, ComponentType: ctype, Value: value})
+	}
+}
+
+// destroyed sends the lifecycle signals for an Entity that is going to be removed
+func (v *View) destroyed(ent *Entity) {
+	if v.listening(ComponentRemovedType) {
+		for _, t := range ent.arch.types {
+			v.lifecycle.Signal(ComponentRemoved{ID: ent.id, ComponentType: t, Value: ent.components[t].value})
+		}
+	}
+	if v.listening(EntityRemovedType) {
+		v.lifecycle.Signal(EntityRemoved{ID: ent.id})
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"github.com/juan-medina/goecs"
+	"reflect"
+	"testing"
+)
+
+func TestWorld_Lifecycle(t *testing.T) {
+	world := goecs.Default()
+
+	got := make([]goecs.Component, 0)
+	world.AddListener(func(_ *goecs.World, signal goecs.Component, _ float32) error {
+		got = append(got, signal)
+		return nil
+	}, goecs.EntityCreatedType, goecs.EntityRemovedType, goecs.ComponentAddedType, goecs.ComponentRemovedType)
+
+	id := world.AddEntity(Pos{X: 1, Y: 1})
+	ent, _ := world.Get(id)
+	ent.Add(Vel{X: 2, Y: 2})
+	ent.Set(Vel{X: 3, Y: 3})
+	ent.Remove(PosType)
+	_ = world.Remove(id)
+
+	if len(got) != 0 {
+		t.Fatalf("error on lifecycle, signals should not be sent before update got %v", got)
+	}
+
+	_ = world.Update(0)
+
+	want := []goecs.Component{
+		goecs.EntityCreated{ID: id},
+		goecs.ComponentAdded{ID: id, ComponentType: PosType},
+		goecs.ComponentAdded{ID: id, ComponentType: VelType},
+		goecs.ComponentRemoved{ID: id, ComponentType: PosType, Value: Pos{X: 1, Y: 1}},
+		goecs.ComponentRemoved{ID: id, ComponentType: VelType, Value: Vel{X: 3, Y: 3}},
+		goecs.EntityRemoved{ID: id},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("error on lifecycle signals got %v, want %v", got, want)
+	}
+}
+
+func TestWorld_LifecycleNotListening(t *testing.T) {
+	world := goecs.Default()
+
+	got := make([]goecs.Component, 0)
+	world.AddListener(func(_ *goecs.World, signal goecs.Component, _ float32) error {
+		got = append(got, signal)
+		return nil
+	}, goecs.EntityRemovedType)
+
+	id := world.AddEntity(Pos{X: 1, Y: 1}, Vel{X: 2, Y: 2})
+	world.AddEntity(Pos{X: 1, Y: 1})
+	_ = world.Remove(id)
+
+	_ = world.Update(0)
+
+	want := []goecs.Component{
+		goecs.EntityRemoved{ID: id},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("error on lifecycle signals got %v, want %v", got, want)
+	}
+}
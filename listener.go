@@ -23,130 +23,396 @@
 package goecs
 
 import (
+	"errors"
 	"fmt"
 	"github.com/juan-medina/goecs/sparse"
 	"reflect"
 	"runtime"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrSubscriptionNotFound is the error when we could not find a Subscription
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrStopPropagation could be returned by a Listener to handle a signal, so the listeners with lower priority
+	// does not get it, it is not reported as an error
+	ErrStopPropagation = errors.New("stop propagation")
+	// ErrSignalLoop is the error when there are signals pending to be sent after the maximum signal rounds
+	ErrSignalLoop = errors.New("signal loop")
 )
 
 // Listener that get notified that a new signal has been received by World.Signal
 type Listener func(world *World, signal Component, delta float32) error
 
-// subscription hold the information of listener subscribed to signals with a priority and id
-type subscription struct {
+// Subscription hold the information of listener subscribed to signals with a priority and id
+type Subscription struct {
 	listener Listener        // listener for this subscription
 	signals  []ComponentType // signals that we are subscribed to
 	priority int32           // priority of this subscription
 	id       int64           // id of the subscription
+	subs     *Subscriptions  // subscriptions that this belongs to, nil if it has been unsubscribed
+}
+
+// Unsubscribe removes the Subscription so the Listener will not be notified anymore
+func (sub *Subscription) Unsubscribe() error {
+	if sub.subs == nil {
+		return ErrSubscriptionNotFound
+	}
+	subs := sub.subs
+	sub.subs = nil
+	for _, t := range sub.signals {
+		subs.unindexSignal(sub, t)
+	}
+	return subs.subscriptions.Remove(sub)
+}
+
+// AddSignals adds the given varg signal types to the Subscription
+func (sub *Subscription) AddSignals(signals ...ComponentType) {
+	for _, t := range signals {
+		if containsType(sub.signals, t) {
+			continue
+		}
+		sub.signals = append(sub.signals, t)
+		if sub.subs != nil {
+			sub.subs.indexSignal(sub, t)
+		}
+	}
+}
+
+// RemoveSignals removes the given varg signal types from the Subscription
+func (sub *Subscription) RemoveSignals(signals ...ComponentType) {
+	kept := make([]ComponentType, 0, len(sub.signals))
+	for _, t := range sub.signals {
+		if !containsType(signals, t) {
+			kept = append(kept, t)
+		} else if sub.subs != nil {
+			sub.subs.unindexSignal(sub, t)
+		}
+	}
+	sub.signals = kept
+}
+
+// Signals returns the signal types of the Subscription
+func (sub Subscription) Signals() []ComponentType {
+	signals := make([]ComponentType, len(sub.signals))
+	copy(signals, sub.signals)
+	return signals
 }
 
 // Subscriptions manage subscriptions of Listeners to signals
 type Subscriptions struct {
-	subscriptions      sparse.Slice // subscriptions is an sparse.Slice of subscriptions
-	lastSubscriptionID int64        // lastSubscriptionID is the last subscription id
-	signals            sparse.Slice // sparse.Slice of signals
-	toSend             sparse.Slice // sparse.Slice of signals is a copy to signals to be send
+	subscriptions      sparse.Slice                      // subscriptions is an sparse.Slice of subscriptions
+	lastSubscriptionID int64                             // lastSubscriptionID is the last subscription id
+	signals            sparse.Slice                      // sparse.Slice of signals
+	toSend             sparse.Slice                      // sparse.Slice of signals is a copy to signals to be send
+	index              map[ComponentType][]*Subscription // subscriptions for each signal type sorted by priority
+	signalMutex        *sync.Mutex                       // signalMutex protect the signals from systems running in parallel
+	sent               map[ComponentType]bool            // signal types sent in the current frame
+	policy             ErrorPolicy                       // policy for the errors of the listeners
+	recover            bool                              // recover from the panics of the listeners
+	emitMutex          *sync.Mutex                       // emitMutex protect the emitted signals
+	emitted            []Component                       // emitted signals pending to be sent by Emit
+	emitting           bool                              // emitting is true while Emit is sending signals
+	holding            bool                              // holding is true while systems run in parallel
+	rounds             int                               // maximum rounds of signals to send on each Update
 }
 
-// Subscribe adds a new subscription given a priority and set of signals types
-func (subs *Subscriptions) Subscribe(listener Listener, priority int32, signals ...ComponentType) {
+// Subscribe adds a new subscription given a priority and set of signals types, returning the Subscription
+func (subs *Subscriptions) Subscribe(listener Listener, priority int32, signals ...ComponentType) *Subscription {
 	// increment the id
 	subs.lastSubscriptionID++
-	// add the subscription
-	subs.subscriptions.Add(subscription{
+	sub := &Subscription{
 		id:       subs.lastSubscriptionID,
 		listener: listener,
-		signals:  signals,
 		priority: priority,
-	})
+		subs:     subs,
+	}
+	// add the subscription
+	subs.subscriptions.Add(sub)
+	// index the subscription by each signal
+	sub.AddSignals(signals...)
 	// keep the subscriptions sorted
 	subs.subscriptions.Sort(subs.sortSubsByPriority)
+	return sub
+}
+
+// indexSignal adds a Subscription to the index of a signal type keeping the priority order
+func (subs *Subscriptions) indexSignal(sub *Subscription, signal ComponentType) {
+	current := subs.index[signal]
+	i := sort.Search(len(current), func(i int) bool {
+		return subs.sortSubsByPriority(sub, current[i])
+	})
+	// copy on write, so signals being processed does not see this change
+	updated := make([]*Subscription, 0, len(current)+1)
+	updated = append(updated, current[:i]...)
+	updated = append(updated, sub)
+	updated = append(updated, current[i:]...)
+	subs.index[signal] = updated
+}
+
+// unindexSignal removes a Subscription from the index of a signal type
+func (subs *Subscriptions) unindexSignal(sub *Subscription, signal ComponentType) {
+	current := subs.index[signal]
+	// copy on write, so signals being processed does not see this change
+	updated := make([]*Subscription, 0, len(current))
+	for _, other := range current {
+		if other != sub {
+			updated = append(updated, other)
+		}
+	}
+	if len(updated) == 0 {
+		delete(subs.index, signal)
+		return
+	}
+	subs.index[signal] = updated
+}
+
+// listening check if there is any subscription for the given signal type
+func (subs Subscriptions) listening(signal ComponentType) bool {
+	return len(subs.index[signal]) > 0
 }
 
 // Signal adds a signal to to be sent
 func (subs *Subscriptions) Signal(signal interface{}) {
+	subs.signalMutex.Lock()
+	defer subs.signalMutex.Unlock()
 	// add the signal
 	subs.signals.Add(signal)
+	if component, ok := signal.(Component); ok {
+		subs.sent[component.Type()] = true
+	}
+}
+
+// Emit sends a signal to the listeners immediately, see World.Emit
+func (subs *Subscriptions) Emit(world *World, signal Component, delta float32) error {
+	subs.signalMutex.Lock()
+	subs.sent[signal.Type()] = true
+	subs.signalMutex.Unlock()
+
+	subs.emitMutex.Lock()
+	subs.emitted = append(subs.emitted, signal)
+	// the signal is sent by the Emit that is already sending, or after the systems running in parallel
+	if subs.emitting || subs.holding {
+		subs.emitMutex.Unlock()
+		return nil
+	}
+	subs.emitting = true
+	subs.emitMutex.Unlock()
+
+	return subs.flush(world, delta)
+}
+
+// hold the emitted signals until release is called, while systems run in parallel
+func (subs *Subscriptions) hold() {
+	subs.emitMutex.Lock()
+	defer subs.emitMutex.Unlock()
+	subs.holding = true
+}
+
+// release send the signals emitted while they were hold
+func (subs *Subscriptions) release(world *World, delta float32) error {
+	subs.emitMutex.Lock()
+	subs.holding = false
+	if subs.emitting || len(subs.emitted) == 0 {
+		subs.emitMutex.Unlock()
+		return nil
+	}
+	subs.emitting = true
+	subs.emitMutex.Unlock()
+
+	return subs.flush(world, delta)
+}
+
+// flush send the emitted signals, including the ones emitted by the listeners meanwhile
+func (subs *Subscriptions) flush(world *World, delta float32) error {
+	// the emitted signals are discarded if we stop on an error or a panic
+	defer func() {
+		subs.emitMutex.Lock()
+		defer subs.emitMutex.Unlock()
+		subs.emitting = false
+		subs.emitted = nil
+	}()
+
+	var errs []error
+	for {
+		subs.emitMutex.Lock()
+		if len(subs.emitted) == 0 {
+			subs.emitMutex.Unlock()
+			return joinErrors(errs...)
+		}
+		next := subs.emitted[0]
+		subs.emitted = subs.emitted[1:]
+		subs.emitMutex.Unlock()
+
+		if err := subs.process(world, next, delta); subs.policy.collect(&errs, err) {
+			return err
+		}
+	}
+}
+
+// wasSent check if a signal of the given type has been sent, with Signal or Emit, in the current frame
+func (subs *Subscriptions) wasSent(signal ComponentType) bool {
+	subs.signalMutex.Lock()
+	defer subs.signalMutex.Unlock()
+	return subs.sent[signal]
+}
+
+// endFrame forgets the signal types sent in the current frame
+func (subs *Subscriptions) endFrame() {
+	subs.signalMutex.Lock()
+	defer subs.signalMutex.Unlock()
+	for t := range subs.sent {
+		delete(subs.sent, t)
+	}
 }
 
 // sortSubsByPriority sorts by subscription priority, if equal by id
 func (subs Subscriptions) sortSubsByPriority(a, b interface{}) bool {
-	first := a.(subscription)
-	second := b.(subscription)
+	first := a.(*Subscription)
+	second := b.(*Subscription)
 	if first.priority == second.priority {
 		return first.id < second.id
 	}
 	return first.priority > second.priority
 }
 
-// Update send the pending signals to the listeners on the world
+// SetSignalRounds sets the maximum rounds of signals to send on each Update, see World.SetSignalRounds
+func (subs *Subscriptions) SetSignalRounds(rounds int) {
+	subs.rounds = rounds
+}
+
+// Update send the pending signals to the listeners on the world, in as many rounds as are needed up to the maximum
 func (subs *Subscriptions) Update(world *World, delta float32) error {
-	// avoid to copy empty signals
-	if subs.signals.Size() == 0 {
-		return nil
+	rounds := subs.rounds
+	if rounds < 1 {
+		rounds = 1
 	}
+
+	var errs []error
+	for round := 0; round < rounds; round++ {
+		// avoid to copy empty signals
+		if subs.signals.Size() == 0 {
+			return joinErrors(errs...)
+		}
+		if err := subs.send(world, delta); subs.policy.collect(&errs, err) {
+			return err
+		}
+		// stop if the context of the Update is done
+		if world != nil && world.Context().Err() != nil {
+			return joinErrors(errs...)
+		}
+	}
+
+	if rounds > 1 && subs.signals.Size() > 0 {
+		errs = append(errs, fmt.Errorf("%w: %d signals pending after %d rounds", ErrSignalLoop, subs.signals.Size(), rounds))
+	}
+	return joinErrors(errs...)
+}
+
+// send a round of the pending signals to the listeners on the world
+func (subs *Subscriptions) send(world *World, delta float32) error {
 	// replace the signals to send, so we do not send the signals triggered by the current signals
 	subs.signals.Replace(subs.toSend)
 
 	// clear the hold so new signals will be here
 	subs.signals.Clear()
 
-	var err error
+	// clear the signals to be send, even if we stop on an error
+	defer subs.toSend.Clear()
+
+	var errs []error
 	// get thee signals to send
 	for ite := subs.toSend.Iterator(); ite != nil; ite = ite.Next() {
-		if err = subs.process(world, ite.Value().(Component), delta); err != nil {
+		// stop if the context of the Update is done
+		if world != nil && world.Context().Err() != nil {
+			return joinErrors(append(errs, world.Context().Err())...)
+		}
+		if err := subs.process(world, ite.Value().(Component), delta); subs.policy.collect(&errs, err) {
 			return err
 		}
 	}
 
-	// clear the signals to be send
-	subs.toSend.Clear()
-
-	return nil
+	return joinErrors(errs...)
 }
 
 // process the subscriptions for this signal
 func (subs Subscriptions) process(world *World, signal Component, delta float32) error {
-	var err error
+	var errs []error
 	// get the signal type
 	signalType := signal.Type()
-	// iterate trough the subscriptions
-	for it := subs.subscriptions.Iterator(); it != nil; it = it.Next() {
-		// get te subscription value
-		sub := it.Value().(subscription)
-		// go to the signal that this subscription is listen to
-		for _, t := range sub.signals {
-			// if we listen to this signal type
-			if t == signalType {
-				// notify the listener, return error if happen
-				if err = sub.listener(world, signal, delta); err != nil {
-					return err
-				}
-				// we do not need to iterate further for this subscription
-				break
-			}
+	// iterate trough the subscriptions of this signal type
+	for _, sub := range subs.index[signalType] {
+		// skip subscriptions that stop listening to this signal type while we process it
+		if sub.subs == nil || !containsType(sub.signals, signalType) {
+			continue
+		}
+		// notify the listener, return error if happen depending on the policy
+		err := subs.notify(world, sub, signal, delta)
+		if errors.Is(err, ErrStopPropagation) {
+			break
+		}
+		if subs.policy.collect(&errs, err) {
+			return err
 		}
 	}
-	// no error happens
+	return joinErrors(errs...)
+}
+
+// notify a signal to the listener of a subscription, wrapping its error in a ListenerError
+func (subs Subscriptions) notify(world *World, sub *Subscription, signal Component, delta float32) error {
+	var err error
+	if subs.recover {
+		err = protect(world.View, listenerName(sub.listener), func() error {
+			return sub.listener(world, signal, delta)
+		})
+	} else {
+		err = sub.listener(world, signal, delta)
+	}
+	if err != nil {
+		return &ListenerError{Name: listenerName(sub.listener), Signal: signal, Err: err}
+	}
 	return nil
 }
 
+// SetErrorPolicy sets the ErrorPolicy for the errors of the listeners
+func (subs *Subscriptions) SetErrorPolicy(policy ErrorPolicy) {
+	subs.policy = policy
+}
+
+// SetRecover sets if the panics of the listeners are converted into a *PanicError
+func (subs *Subscriptions) SetRecover(enabled bool) {
+	subs.recover = enabled
+}
+
+// listenerName returns the name of the function of a Listener
+func listenerName(listener Listener) string {
+	return runtime.FuncForPC(reflect.ValueOf(listener).Pointer()).Name()
+}
+
 // Clear the subscriptions & signals
 func (subs *Subscriptions) Clear() {
+	for it := subs.subscriptions.Iterator(); it != nil; it = it.Next() {
+		it.Value().(*Subscription).subs = nil
+	}
 	subs.subscriptions.Clear()
 	subs.signals.Clear()
 	subs.toSend.Clear()
+	for t := range subs.index {
+		delete(subs.index, t)
+	}
+	subs.endFrame()
 }
 
 // String returns the string representation of the subscriptions
 func (subs Subscriptions) String() string {
 	str := ""
 	for it := subs.subscriptions.Iterator(); it != nil; it = it.Next() {
-		l := it.Value().(subscription)
+		l := it.Value().(*Subscription)
 		if str != "" {
 			str += ","
 		}
-		name := runtime.FuncForPC(reflect.ValueOf(l.listener).Pointer()).Name()
+		name := listenerName(l.listener)
 		signals := ""
 		for _, v := range l.signals {
 			if signals != "" {
@@ -165,5 +431,9 @@ func NewSubscriptions(listeners, signals int) *Subscriptions {
 		subscriptions: sparse.NewSlice(listeners),
 		signals:       sparse.NewSlice(signals),
 		toSend:        sparse.NewSlice(signals),
+		index:         make(map[ComponentType][]*Subscription),
+		signalMutex:   &sync.Mutex{},
+		sent:          make(map[ComponentType]bool),
+		emitMutex:     &sync.Mutex{},
 	}
 }
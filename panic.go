@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// PanicError is the error of a System or a Listener that panics when the World recovers from panics
+type PanicError struct {
+	Name   string      // Name of the System or Listener
+	Value  interface{} // Value of the panic
+	Stack  []byte      // Stack of the panic
+	Entity EntityID    // Entity is the last Entity iterated, 0 if unknown or if systems were running in parallel
+}
+
+// Error returns the error message
+func (e *PanicError) Error() string {
+	if e.Entity != 0 {
+		return fmt.Sprintf("panic in %s on entity %d: %v", e.Name, e.Entity, e.Value)
+	}
+	return fmt.Sprintf("panic in %s: %v", e.Name, e.Value)
+}
+
+// Unwrap returns the value of the panic if it is an error
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// protect calls a function of a System or Listener with the given name, converting a panic into a *PanicError
+func protect(view *View, name string, fn func() error) (err error) {
+	last := view.lastEntity()
+	view.forget()
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{Name: name, Value: value, Stack: debug.Stack(), Entity: view.lastEntity()}
+		}
+		// a Listener called from a System keeps the last Entity iterated by the System
+		atomic.StoreUint64(&view.last, uint64(last))
+	}()
+	return fn()
+}
+
+// track sets if the View records the last Entity iterated
+func (v *View) track(enabled bool) {
+	v.tracking = enabled
+	v.forget()
+}
+
+// forget the last Entity iterated
+func (v *View) forget() {
+	atomic.StoreUint64(&v.last, 0)
+}
+
+// share sets if systems are running in parallel, then the last Entity iterated could be from any of them so it is
+// not recorded
+func (v *View) share(parallel bool) {
+	if parallel {
+		atomic.StoreInt32(&v.parallel, 1)
+	} else {
+		atomic.StoreInt32(&v.parallel, 0)
+	}
+}
+
+// shared returns if systems are running in parallel
+func (v *View) shared() bool {
+	return atomic.LoadInt32(&v.parallel) != 0
+}
+
+// lastEntity returns the EntityID of the last Entity iterated, 0 if unknown
+func (v *View) lastEntity() EntityID {
+	if v.shared() {
+		return 0
+	}
+	return EntityID(atomic.LoadUint64(&v.last))
+}
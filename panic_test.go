@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"errors"
+	"github.com/juan-medina/goecs"
+	"strings"
+	"testing"
+)
+
+func PanicSystem(world *goecs.World, _ float32) error {
+	for it := world.Iterator(PosType); it != nil; it = it.Next() {
+		_ = it.Value().Get(VelType).(Vel)
+	}
+	return nil
+}
+
+func PanicListener(_ *goecs.World, _ goecs.Component, _ float32) error {
+	panic(errFailure)
+}
+
+func TestWorld_SetRecover(t *testing.T) {
+	world := goecs.Default()
+	world.SetRecover(true)
+
+	world.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+	id := world.AddEntity(Pos{X: 2, Y: 2})
+	world.AddSystem(PanicSystem)
+
+	err := world.Update(0)
+
+	var panicErr *goecs.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("error on update got %v, want panic error", err)
+	}
+	if panicErr.Name != "github.com/juan-medina/goecs_test.PanicSystem" || panicErr.Entity != id {
+		t.Fatalf("got panic error %v, want PanicSystem on entity %d", panicErr, id)
+	}
+	if !strings.Contains(string(panicErr.Stack), "PanicSystem") {
+		t.Fatalf("got stack %s, want PanicSystem", panicErr.Stack)
+	}
+
+	world.Clear()
+	world.AddListener(PanicListener, dummySignalType)
+	world.Signal(dummySignal{})
+
+	err = world.Update(0)
+
+	var listenerErr *goecs.ListenerError
+	if !errors.As(err, &listenerErr) || !errors.As(err, &panicErr) || !errors.Is(err, errFailure) {
+		t.Fatalf("error on update got %v, want listener panic error", err)
+	}
+	if panicErr.Entity != 0 {
+		t.Fatalf("got panic entity %d, want 0", panicErr.Entity)
+	}
+}
+
+func TestWorld_SetRecoverDisabled(t *testing.T) {
+	world := goecs.Default()
+	world.AddListener(PanicListener, dummySignalType)
+	world.Signal(dummySignal{})
+
+	defer func() {
+		if value := recover(); value != errFailure {
+			t.Fatalf("got panic %v, want %v", value, errFailure)
+		}
+	}()
+
+	_ = world.Update(0)
+}
+
+func TestWorld_SetRecoverParallel(t *testing.T) {
+	world := goecs.Default()
+	world.SetRecover(true)
+	world.SetParallel(4)
+
+	for i := 0; i < 100; i++ {
+		world.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+	}
+	world.AddEntity(Pos{X: 2, Y: 2})
+
+	world.AddSystem(PanicSystem, goecs.Reads(PosType, VelType))
+	world.AddSystem(func(world *goecs.World, _ float32) error {
+		for it := world.Iterator(VelType); it != nil; it = it.Next() {
+		}
+		return nil
+	}, goecs.Reads(VelType))
+
+	err := world.Update(0)
+
+	// the last entity iterated could be from any of the systems
+	var panicErr *goecs.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("error on update got %v, want panic error", err)
+	}
+	if panicErr.Entity != 0 {
+		t.Fatalf("got panic entity %d, want 0", panicErr.Entity)
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import "errors"
+
+var (
+	// ErrQueryNotFound is the error when we could not find a Query
+	ErrQueryNotFound = errors.New("query not found")
+)
+
+// Query is a cached query registered in a View
+//
+// The archetypes that match the Query are maintained by the View when they are created, and entities move between
+// archetypes when their components change, so iterating a Query only visit the entities that match it
+type Query struct {
+	view       *View
+	filter     Filter
+	archetypes []*archetype // archetypes that match the filter
+}
+
+// Query register a new Query for the entities that match the given varg Term
+func (v *View) Query(terms ...Term) *Query {
+	q := &Query{
+		view:       v,
+		filter:     NewFilter(terms...),
+		archetypes: make([]*archetype, 0),
+	}
+	for _, arch := range v.archetypes {
+		if q.filter.matches(arch) {
+			q.archetypes = append(q.archetypes, arch)
+		}
+	}
+	v.queries = append(v.queries, q)
+	return q
+}
+
+// RemoveQuery unregister a Query from the View
+func (v *View) RemoveQuery(query *Query) error {
+	for i, q := range v.queries {
+		if q == query {
+			v.queries = append(v.queries[:i], v.queries[i+1:]...)
+			return nil
+		}
+	}
+	return ErrQueryNotFound
+}
+
+// Iterator return a view.Iterator for the entities that match the Query
+func (q *Query) Iterator() *Iterator {
+	it := Iterator{
+		data:    q.view,
+		filter:  q.filter,
+		query:   q,
+		slot:    -1,
+		version: -1,
+		since:   q.view.since,
+	}
+	return it.Next()
+}
+
+// Size is the number of Entity that match the Query
+func (q *Query) Size() int {
+	size := 0
+	if q.filter.tracks() {
+		// we need to check the changes for each Entity
+		for it := q.Iterator(); it != nil; it = it.Next() {
+			size++
+		}
+		return size
+	}
+	for _, arch := range q.archetypes {
+		size += arch.size
+	}
+	return size
+}
+
+// query is the base for the typed queries, it holds the ComponentType to query and the registered Query
+type query struct {
+	cached *Query
+	types  []ComponentType
+}
+
+// newQuery register a query in a View with the types of the components and a set of extra Term
+func newQuery(view *View, types []ComponentType, terms []Term) query {
+	return query{
+		cached: view.Query(append([]Term{With(types...)}, terms...)...),
+		types:  types,
+	}
+}
+
+// Size is the number of Entity that match the query
+func (q query) Size() int {
+	return q.cached.Size()
+}
+
+// Query returns the Query registered in the View for this typed query
+func (q query) Query() *Query {
+	return q.cached
+}
+
+// component returns the value of the given ComponentType from an Entity as T
+func component[T any](ent *Entity, ctype ComponentType) T {
+	value, _ := ent.value(ctype).(T)
+	return value
+}
+
+// Query1 is a typed query for the entities in a View that has a component of type A
+type Query1[A any] struct {
+	query
+}
+
+// Query1Iterator allow to iterate trough the results of a Query1
+type Query1Iterator[A any] struct {
+	q  *Query1[A]
+	it *Iterator
+}
+
+// NewQuery1 creates a new Query1 for a View with an optional set of Term to filter the results
+//
+// The Query1 is registered in the View so it should be created once and reused
+func NewQuery1[A any](view *View, terms ...Term) *Query1[A] {
+	return &Query1[A]{
+		query: newQuery(view, []ComponentType{TypeOf[A]()}, terms),
+	}
+}
+
+// Iterator return a Query1Iterator to the first result, nil if there is none
+func (q *Query1[A]) Iterator() *Query1Iterator[A] {
+	return (&Query1Iterator[A]{q: q, it: q.cached.Iterator()}).valid()
+}
+
+// Next return a Query1Iterator to the next result, nil if there is none
+func (qi *Query1Iterator[A]) Next() *Query1Iterator[A] {
+	qi.it = qi.it.Next()
+	return qi.valid()
+}
+
+// valid returns this Query1Iterator if it has a value, otherwise nil
+func (qi *Query1Iterator[A]) valid() *Query1Iterator[A] {
+	if qi.it == nil {
+		return nil
+	}
+	return qi
+}
+
+// ID returns the EntityID of the current result
+func (qi *Query1Iterator[A]) ID() EntityID {
+	return qi.it.Value().ID()
+}
+
+// Entity returns the Entity of the current result
+func (qi *Query1Iterator[A]) Entity() *Entity {
+	return qi.it.Value()
+}
+
+// Get returns the component of the current result
+func (qi *Query1Iterator[A]) Get() A {
+	ent := qi.it.Value()
+	return component[A](ent, qi.q.types[0])
+}
+
+// Query2 is a typed query for the entities in a View that has components of types A and B
+type Query2[A, B any] struct {
+	query
+}
+
+// Query2Iterator allow to iterate trough the results of a Query2
+type Query2Iterator[A, B any] struct {
+	q  *Query2[A, B]
+	it *Iterator
+}
+
+// NewQuery2 creates a new Query2 for a View with an optional set of Term to filter the results
+//
+// The Query2 is registered in the View so it should be created once and reused
+func NewQuery2[A, B any](view *View, terms ...Term) *Query2[A, B] {
+	return &Query2[A, B]{
+		query: newQuery(view, []ComponentType{TypeOf[A](), TypeOf[B]()}, terms),
+	}
+}
+
+// Iterator return a Query2Iterator to the first result, nil if there is none
+func (q *Query2[A, B]) Iterator() *Query2Iterator[A, B] {
+	return (&Query2Iterator[A, B]{q: q, it: q.cached.Iterator()}).valid()
+}
+
+// Next return a Query2Iterator to the next result, nil if there is none
+func (qi *Query2Iterator[A, B]) Next() *Query2Iterator[A, B] {
+	qi.it = qi.it.Next()
+	return qi.valid()
+}
+
+// valid returns this Query2Iterator if it has a value, otherwise nil
+func (qi *Query2Iterator[A, B]) valid() *Query2Iterator[A, B] {
+	if qi.it == nil {
+		return nil
+	}
+	return qi
+}
+
+// ID returns the EntityID of the current result
+func (qi *Query2Iterator[A, B]) ID() EntityID {
+	return qi.it.Value().ID()
+}
+
+// Entity returns the Entity of the current result
+func (qi *Query2Iterator[A, B]) Entity() *Entity {
+	return qi.it.Value()
+}
+
+// Get returns the components of the current result
+func (qi *Query2Iterator[A, B]) Get() (A, B) {
+	ent := qi.it.Value()
+	return component[A](ent, qi.q.types[0]), component[B](ent, qi.q.types[1])
+}
+
+// Query3 is a typed query for the entities in a View that has components of types A, B and C
+type Query3[A, B, C any] struct {
+	query
+}
+
+// Query3Iterator allow to iterate trough the results of a Query3
+type Query3Iterator[A, B, C any] struct {
+	q  *Query3[A, B, C]
+	it *Iterator
+}
+
+// NewQuery3 creates a new Query3 for a View with an optional set of Term to filter the results
+//
+// The Query3 is registered in the View so it should be created once and reused
+func NewQuery3[A, B, C any](view *View, terms ...Term) *Query3[A, B, C] {
+	return &Query3[A, B, C]{
+		query: newQuery(view, []ComponentType{TypeOf[A](), TypeOf[B](), TypeOf[C]()}, terms),
+	}
+}
+
+// Iterator return a Query3Iterator to the first result, nil if there is none
+func (q *Query3[A, B, C]) Iterator() *Query3Iterator[A, B, C] {
+	return (&Query3Iterator[A, B, C]{q: q, it: q.cached.Iterator()}).valid()
+}
+
+// Next return a Query3Iterator to the next result, nil if there is none
+func (qi *Query3Iterator[A, B, C]) Next() *Query3Iterator[A, B, C] {
+	qi.it = qi.it.Next()
+	return qi.valid()
+}
+
+// valid returns this Query3Iterator if it has a value, otherwise nil
+func (qi *Query3Iterator[A, B, C]) valid() *Query3Iterator[A, B, C] {
+	if qi.it == nil {
+		return nil
+	}
+	return qi
+}
+
+// ID returns the EntityID of the current result
+func (qi *Query3Iterator[A, B, C]) ID() EntityID {
+	return qi.it.Value().ID()
+}
+
+// Entity returns the Entity of the current result
+func (qi *Query3Iterator[A, B, C]) Entity() *Entity {
+	return qi.it.Value()
+}
+
+// Get returns the components of the current result
+func (qi *Query3Iterator[A, B, C]) Get() (A, B, C) {
+	ent := qi.it.Value()
+	return component[A](ent, qi.q.types[0]), component[B](ent, qi.q.types[1]), component[C](ent, qi.q.types[2])
+}
+
+// Query4 is a typed query for the entities in a View that has components of types A, B, C and D
+type Query4[A, B, C, D any] struct {
+	query
+}
+
+// Query4Iterator allow to iterate trough the results of a Query4
+type Query4Iterator[A, B, C, D any] struct {
+	q  *Query4[A, B, C, D]
+	it *Iterator
+}
+
+// NewQuery4 creates a new Query4 for a View with an optional set of Term to filter the results
+//
+// The Query4 is registered in the View so it should be created once and reused
+func NewQuery4[A, B, C, D any](view *View, terms ...Term) *Query4[A, B, C, D] {
+	return &Query4[A, B, C, D]{
+		query: newQuery(view, []ComponentType{TypeOf[A](), TypeOf[B](), TypeOf[C](), TypeOf[D]()}, terms),
+	}
+}
+
+// Iterator return a Query4Iterator to the first result, nil if there is none
+func (q *Query4[A, B, C, D]) Iterator() *Query4Iterator[A, B, C, D] {
+	return (&Query4Iterator[A, B, C, D]{q: q, it: q.cached.Iterator()}).valid()
+}
+
+// Next return a Query4Iterator to the next result, nil if there is none
+func (qi *Query4Iterator[A, B, C, D]) Next() *Query4Iterator[A, B, C, D] {
+	qi.it = qi.it.Next()
+	return qi.valid()
+}
+
+// valid returns this Query4Iterator if it has a value, otherwise nil
+func (qi *Query4Iterator[A, B, C, D]) valid() *Query4Iterator[A, B, C, D] {
+	if qi.it == nil {
+		return nil
+	}
+	return qi
+}
+
+// ID returns the EntityID of the current result
+func (qi *Query4Iterator[A, B, C, D]) ID() EntityID {
+	return qi.it.Value().ID()
+}
+
+// Entity returns the Entity of the current result
+func (qi *Query4Iterator[A, B, C, D]) Entity() *Entity {
+	return qi.it.Value()
+}
+
+// Get returns the components of the current result
+func (qi *Query4Iterator[A, B, C, D]) Get() (A, B, C, D) {
+	ent := qi.it.Value()
+	return component[A](ent, qi.q.types[0]), component[B](ent, qi.q.types[1]),
+		component[C](ent, qi.q.types[2]), component[D](ent, qi.q.types[3])
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"errors"
+	"github.com/juan-medina/goecs"
+	"reflect"
+	"testing"
+)
+
+type Frozen struct{}
+
+func TestQuery1(t *testing.T) {
+	world := goecs.Default()
+
+	id1 := world.AddEntity(Pos{X: 1, Y: 1})
+	world.AddEntity(Vel{X: 2, Y: 2})
+	id3 := world.AddEntity(Pos{X: 3, Y: 3}, Vel{X: 4, Y: 4})
+
+	ids := make([]goecs.EntityID, 0)
+	got := make([]Pos, 0)
+
+	q := goecs.NewQuery1[Pos](world.View)
+	for it := q.Iterator(); it != nil; it = it.Next() {
+		ids = append(ids, it.ID())
+		got = append(got, it.Get())
+	}
+
+	if !entitiesEqual(ids, []goecs.EntityID{id1, id3}) {
+		t.Fatalf("error on query ids got %v, want %v", ids, []goecs.EntityID{id1, id3})
+	}
+
+	want := []Pos{{X: 1, Y: 1}, {X: 3, Y: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("error on query got %v, want %v", got, want)
+	}
+}
+
+func TestQuery2(t *testing.T) {
+	world := goecs.Default()
+
+	world.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+	world.AddEntity(Pos{X: 2, Y: 2})
+	world.AddEntity(Pos{X: 3, Y: 3}, Vel{X: 4, Y: 4})
+
+	q := goecs.NewQuery2[Pos, Vel](world.View)
+	world.AddSystem(func(world *goecs.World, delta float32) error {
+		for it := q.Iterator(); it != nil; it = it.Next() {
+			pos, vel := it.Get()
+			goecs.Set(it.Entity(), Pos{X: pos.X + vel.X, Y: pos.Y + vel.Y})
+		}
+		return nil
+	})
+
+	_ = world.Update(0)
+
+	expectWorldPositions(t, world, []Pos{
+		{X: 1, Y: 1},
+		{X: 2, Y: 2},
+		{X: 7, Y: 7},
+	})
+}
+
+func TestQuery3(t *testing.T) {
+	world := goecs.Default()
+
+	world.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+	id := world.AddEntity(Pos{X: 2, Y: 2}, Vel{X: 3, Y: 3})
+	ent, _ := world.Get(id)
+	goecs.Set(ent, Health{Points: 10})
+
+	count := 0
+	q := goecs.NewQuery3[Pos, Vel, Health](world.View)
+	for it := q.Iterator(); it != nil; it = it.Next() {
+		pos, vel, health := it.Get()
+		if it.ID() != id || pos.X != 2 || vel.X != 3 || health.Points != 10 {
+			t.Fatalf("error on query got %v %v %v %v", it.ID(), pos, vel, health)
+		}
+		count++
+	}
+
+	if count != 1 {
+		t.Fatalf("error on query got %d results, want 1", count)
+	}
+}
+
+func TestQuery4(t *testing.T) {
+	world := goecs.Default()
+
+	id := world.AddEntity(Pos{X: 2, Y: 2}, Vel{X: 3, Y: 3})
+	ent, _ := world.Get(id)
+	goecs.Set(ent, Health{Points: 10})
+
+	q := goecs.NewQuery4[Pos, Vel, Health, Frozen](world.View)
+	if it := q.Iterator(); it != nil {
+		t.Fatalf("error on query, expect no results got %v", it.Entity())
+	}
+
+	goecs.Set(ent, Frozen{})
+
+	count := 0
+	for it := q.Iterator(); it != nil; it = it.Next() {
+		pos, vel, health, _ := it.Get()
+		if pos.X != 2 || vel.X != 3 || health.Points != 10 {
+			t.Fatalf("error on query got %v %v %v", pos, vel, health)
+		}
+		count++
+	}
+
+	if count != 1 {
+		t.Fatalf("error on query got %d results, want 1", count)
+	}
+}
+
+func TestQuery2_Terms(t *testing.T) {
+	world := goecs.Default()
+
+	world.AddEntity(Pos{X: 1, Y: 1}, Vel{X: 1, Y: 1})
+	id2 := world.AddEntity(Pos{X: 2, Y: 2}, Vel{X: 2, Y: 2})
+	world.AddEntity(Pos{X: 3, Y: 3})
+
+	ent, _ := world.Get(id2)
+	goecs.Set(ent, Frozen{})
+
+	got := make([]Vel, 0)
+	q := goecs.NewQuery2[Pos, Vel](world.View, goecs.Without(FrozenType), goecs.Optional(VelType))
+	for it := q.Iterator(); it != nil; it = it.Next() {
+		_, vel := it.Get()
+		got = append(got, vel)
+	}
+
+	want := []Vel{{X: 1, Y: 1}, {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("error on query got %v, want %v", got, want)
+	}
+}
+
+func TestView_Query(t *testing.T) {
+	view := goecs.NewView(10)
+
+	id1 := view.AddEntity(Pos{X: 1, Y: 1}, Vel{X: 1, Y: 1})
+	view.AddEntity(Pos{X: 2, Y: 2})
+
+	q := view.Query(goecs.With(PosType, VelType), goecs.Without(FrozenType))
+
+	expectQuery := func(t *testing.T, expect []goecs.EntityID) {
+		t.Helper()
+		got := make([]goecs.EntityID, 0)
+		for it := q.Iterator(); it != nil; it = it.Next() {
+			got = append(got, it.Value().ID())
+		}
+		if !entitiesEqual(got, expect) {
+			t.Fatalf("error on query got %v, want %v", got, expect)
+		}
+		if q.Size() != len(expect) {
+			t.Fatalf("error on query size got %d, want %d", q.Size(), len(expect))
+		}
+	}
+
+	expectQuery(t, []goecs.EntityID{id1})
+
+	// a new archetype that match the query
+	id3 := view.AddEntity(Pos{X: 3, Y: 3}, Vel{X: 3, Y: 3}, score{points: 1})
+	expectQuery(t, []goecs.EntityID{id1, id3})
+
+	// entities that change their components
+	ent, _ := view.Get(id1)
+	goecs.Set(ent, Frozen{})
+	expectQuery(t, []goecs.EntityID{id3})
+
+	goecs.Remove[Frozen](ent)
+	expectQuery(t, []goecs.EntityID{id1, id3})
+
+	_ = view.Remove(id3)
+	expectQuery(t, []goecs.EntityID{id1})
+
+	if err := view.RemoveQuery(q); err != nil {
+		t.Fatalf("error on remove query got %v, want nil", err)
+	}
+
+	if err := view.RemoveQuery(q); !errors.Is(err, goecs.ErrQueryNotFound) {
+		t.Fatalf("error on remove query got %v, want %v", err, goecs.ErrQueryNotFound)
+	}
+}
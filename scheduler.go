@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Reads returns a SystemOption to declare the varg ComponentType that a System reads
+//
+// Systems that declare their access could run in parallel with other systems that does not write what they read or
+// read or write what they write, see World.SetParallel
+func Reads(types ...ComponentType) SystemOption {
+	return func(sr *systemRegistration) {
+		sr.reads = append(sr.reads, types...)
+		sr.declared = true
+	}
+}
+
+// Writes returns a SystemOption to declare the varg ComponentType that a System writes, see Reads
+func Writes(types ...ComponentType) SystemOption {
+	return func(sr *systemRegistration) {
+		sr.writes = append(sr.writes, types...)
+		sr.declared = true
+	}
+}
+
+// SetParallel sets the number of workers to run systems in parallel, lower than 2 run the systems sequentially
+func (sys *Systems) SetParallel(workers int) {
+	sys.workers = workers
+}
+
+// nextBatch returns the registrations that could run together starting from a position in an order, and the
+// position after them
+func (sys *Systems) nextBatch(world *World, order []*systemRegistration, start int) ([]*systemRegistration, int) {
+	batch := sys.batch[:0]
+	for i := start; i < len(order); i++ {
+		sr := order[i]
+		// skip disabled systems
+		if sr.disabled || sr.removed {
+			continue
+		}
+		if len(batch) > 0 && !sys.joins(batch, sr) {
+			sys.batch = batch
+			return batch, i
+		}
+		// skip systems that does not meet their conditions
+		if sr.skip = sr.skipped(world); sr.skip != "" {
+			continue
+		}
+		batch = append(batch, sr)
+		// systems that does not declare their access run alone
+		if sys.workers < 2 || !sr.declared {
+			sys.batch = batch
+			return batch, i + 1
+		}
+	}
+	sys.batch = batch
+	return batch, len(order)
+}
+
+// joins check if a registration could run in parallel with a batch of registrations
+func (sys *Systems) joins(batch []*systemRegistration, sr *systemRegistration) bool {
+	if !sr.declared || sr.stage != batch[0].stage {
+		return false
+	}
+	for _, other := range batch {
+		if sr.conflicts(other) || sr.follows[other.id] || other.follows[sr.id] {
+			return false
+		}
+	}
+	return true
+}
+
+// conflicts check if a registration writes what other reads or writes, or reads what other writes
+func (sr *systemRegistration) conflicts(other *systemRegistration) bool {
+	return overlaps(sr.writes, other.reads) || overlaps(sr.writes, other.writes) || overlaps(sr.reads, other.writes)
+}
+
+// overlaps check if two lists of ComponentType have any in common
+func overlaps(a, b []ComponentType) bool {
+	for _, t := range a {
+		if containsType(b, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// runParallel runs a batch of registrations in the workers, returning the error of the first of them that fails or
+// all the errors joined, depending on the policy
+func (sys *Systems) runParallel(world *World, delta float32, batch []*systemRegistration) error {
+	workers := sys.workers
+	if workers > len(batch) {
+		workers = len(batch)
+	}
+
+	// the signals emitted by the systems are sent after the batch, so the listeners do not run in parallel
+	world.View.share(true)
+	world.subscriptions.hold()
+
+	// each system get its own World that report the changes since it last run
+	worlds := make([]World, len(batch))
+	for i, sr := range batch {
+		worlds[i] = *world
+		worlds[i].scoped, worlds[i].since = true, sr.since
+	}
+
+	errs := make([]error, len(batch))
+	next := int64(-1)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := int(atomic.AddInt64(&next, 1)); i < len(batch); i = int(atomic.AddInt64(&next, 1)) {
+				errs[i] = sys.invoke(&worlds[i], delta, batch[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	world.View.share(false)
+	errs = append(errs, world.subscriptions.release(world, delta))
+
+	if sys.policy == Continue {
+		return joinErrors(errs...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"errors"
+	"github.com/juan-medina/goecs"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errNotParallel = errors.New("systems are not running in parallel")
+
+// rendezvous returns a System that sets the given component on all the entities with Pos and Vel, and then waits
+// for the other systems of the rendezvous to run, failing if they are not running in parallel
+func rendezvous(wg *sync.WaitGroup, timeout time.Duration, set func(ent *goecs.Entity)) goecs.System {
+	return func(world *goecs.World, _ float32) error {
+		for it := world.Iterator(PosType, VelType); it != nil; it = it.Next() {
+			set(it.Value())
+		}
+		world.Signal(dummySignal{})
+
+		wg.Done()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(timeout):
+			return errNotParallel
+		}
+	}
+}
+
+func TestWorld_SetParallelConflicts(t *testing.T) {
+	world := goecs.Default()
+	world.SetParallel(4)
+
+	world.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	world.AddSystem(rendezvous(wg, 50*time.Millisecond, func(ent *goecs.Entity) {
+		ent.Set(Pos{X: 1, Y: 1})
+	}), goecs.Writes(PosType))
+	world.AddSystem(rendezvous(wg, 50*time.Millisecond, func(ent *goecs.Entity) {
+		ent.Set(Vel{X: 2, Y: 2})
+	}), goecs.Reads(PosType), goecs.Writes(VelType))
+
+	if err := world.Update(0); !errors.Is(err, errNotParallel) {
+		t.Fatalf("error on update got %v, want %v", err, errNotParallel)
+	}
+}
+
+func TestWorld_SetParallel(t *testing.T) {
+	world := goecs.Default()
+	world.SetParallel(4)
+
+	for i := 0; i < 100; i++ {
+		world.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+	}
+
+	calls := 0
+	world.AddListener(func(_ *goecs.World, _ goecs.Component, _ float32) error {
+		calls++
+		return nil
+	}, dummySignalType)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	world.AddSystem(rendezvous(wg, 5*time.Second, func(ent *goecs.Entity) {
+		ent.Set(Pos{X: 1, Y: 1})
+	}), goecs.Writes(PosType))
+	world.AddSystem(rendezvous(wg, 5*time.Second, func(ent *goecs.Entity) {
+		ent.Set(Vel{X: 2, Y: 2})
+	}), goecs.Writes(VelType))
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	if calls != 2 {
+		t.Fatalf("got %d signals, want 2", calls)
+	}
+
+	for it := world.Iterator(PosType, VelType); it != nil; it = it.Next() {
+		ent := it.Value()
+		if pos := ent.Get(PosType).(Pos); pos != (Pos{X: 1, Y: 1}) {
+			t.Fatalf("got %v, want %v", pos, Pos{X: 1, Y: 1})
+		}
+		if vel := ent.Get(VelType).(Vel); vel != (Vel{X: 2, Y: 2}) {
+			t.Fatalf("got %v, want %v", vel, Vel{X: 2, Y: 2})
+		}
+	}
+}
+
+func TestWorld_SetParallelOrder(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+	world.SetParallel(4)
+
+	idA := world.AddSystem(systemA, goecs.Writes(PosType))
+	world.AddSystem(systemB, goecs.Reads(PosType))
+	world.AddSystem(systemC)
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	expect := []string{"update a", "update b", "update c"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	systemCalls = make([]string, 0)
+	world = goecs.Default()
+	world.SetParallel(4)
+
+	world.AddSystem(systemC, goecs.Reads(PosType), goecs.InStage(goecs.PostUpdateStage))
+	idA = world.AddSystem(systemA, goecs.Writes(PosType))
+	world.AddSystem(systemB, goecs.Reads(VelType), goecs.After(idA))
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	expect = []string{"update a", "update b", "update c"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_SetParallelChanges(t *testing.T) {
+	world := goecs.Default()
+	world.SetParallel(4)
+
+	id := world.AddEntity(Pos{X: 0, Y: 0})
+
+	mutex := sync.Mutex{}
+	changes := make(map[string]int)
+	changed := func(name string) goecs.System {
+		return func(world *goecs.World, _ float32) error {
+			count := 0
+			for it := world.Filter(goecs.Changed(PosType)); it != nil; it = it.Next() {
+				count++
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			changes[name] = count
+			return nil
+		}
+	}
+
+	world.AddSystem(changed("a"), goecs.Reads(PosType))
+	idB := world.AddSystem(changed("b"), goecs.Reads(PosType))
+
+	type frame struct {
+		name   string
+		setup  func()
+		expect map[string]int
+	}
+	for _, f := range []frame{
+		{
+			name:   "both report the added entity",
+			setup:  func() {},
+			expect: map[string]int{"a": 1, "b": 1},
+		},
+		{
+			name: "only a runs",
+			setup: func() {
+				_ = world.DisableSystem(idB)
+				ent, _ := world.Get(id)
+				ent.Set(Pos{X: 1, Y: 1})
+			},
+			expect: map[string]int{"a": 1},
+		},
+		{
+			name: "b reports the change that a already did",
+			setup: func() {
+				_ = world.EnableSystem(idB)
+			},
+			expect: map[string]int{"a": 0, "b": 1},
+		},
+	} {
+		t.Run(f.name, func(t *testing.T) {
+			changes = make(map[string]int)
+			f.setup()
+			if err := world.Update(0); err != nil {
+				t.Fatalf("error on update got %v, want nil", err)
+			}
+			if !reflect.DeepEqual(changes, f.expect) {
+				t.Fatalf("got %v, want %v", changes, f.expect)
+			}
+		})
+	}
+}
+
+var emitSignalType = goecs.NewComponentType()
+
+type emitSignal struct {
+	done *bool
+}
+
+func (e emitSignal) Type() goecs.ComponentType {
+	return emitSignalType
+}
+
+func TestWorld_SetParallelEmit(t *testing.T) {
+	world := goecs.Default()
+	world.SetParallel(4)
+
+	var running int32
+	notified := 0
+	world.AddListener(func(_ *goecs.World, signal goecs.Component, _ float32) error {
+		if atomic.LoadInt32(&running) != 0 {
+			return errors.New("listener runs while the systems are running")
+		}
+		*signal.(emitSignal).done = true
+		notified++
+		return nil
+	}, emitSignalType)
+
+	// the signals emitted by systems running in parallel are sent after all of them have finished
+	emitter := func(world *goecs.World, _ float32) error {
+		atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for i := 0; i < 10; i++ {
+			done := false
+			if err := world.Emit(emitSignal{done: &done}); err != nil {
+				return err
+			}
+			if done {
+				return errors.New("listener runs before the batch ends")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}
+	world.AddSystem(emitter, goecs.Reads(PosType))
+	world.AddSystem(emitter, goecs.Reads(PosType))
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	if notified != 20 {
+		t.Fatalf("got %d signals notified, want 20", notified)
+	}
+
+	world.AddListener(FailureListener, emitSignalType)
+	if err := world.Update(0); !errors.Is(err, errFailure) {
+		t.Fatalf("error on update got %v, want %v", err, errFailure)
+	}
+}
+
+func TestWorld_SetParallelAgain(t *testing.T) {
+	world := goecs.Default()
+	world.SetParallel(4)
+
+	// each system waits for the others enabled in the frame, failing if they are not running in parallel
+	var barrier *sync.WaitGroup
+	waiter := func(_ *goecs.World, _ float32) error {
+		barrier.Done()
+		done := make(chan struct{})
+		go func() {
+			barrier.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errNotParallel
+		}
+	}
+
+	world.AddSystem(waiter, goecs.Reads(PosType))
+	idB := world.AddSystem(waiter, goecs.Reads(PosType))
+
+	for _, f := range []struct {
+		name    string
+		setup   func()
+		systems int
+	}{
+		{name: "both run in parallel", setup: func() {}, systems: 2},
+		{name: "b is disabled", setup: func() { _ = world.DisableSystem(idB) }, systems: 1},
+		{name: "b runs in parallel again", setup: func() { _ = world.EnableSystem(idB) }, systems: 2},
+		{name: "a new system runs in parallel", setup: func() {
+			world.AddSystem(waiter, goecs.Reads(PosType))
+		}, systems: 3},
+	} {
+		t.Run(f.name, func(t *testing.T) {
+			f.setup()
+			barrier = &sync.WaitGroup{}
+			barrier.Add(f.systems)
+			if err := world.Update(0); err != nil {
+				t.Fatalf("error on update got %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestWorld_SetParallelTransitiveOrder(t *testing.T) {
+	world := goecs.Default()
+	world.SetParallel(4)
+
+	mutex := sync.Mutex{}
+	calls := make([]string, 0)
+	call := func(name string, wait time.Duration) goecs.System {
+		return func(_ *goecs.World, _ float32) error {
+			time.Sleep(wait)
+			mutex.Lock()
+			defer mutex.Unlock()
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	// c runs after a through b, even when b does not run
+	idA := world.AddSystem(call("a", 50*time.Millisecond), goecs.Reads(PosType))
+	idB := world.AddSystem(call("b", 0), goecs.Writes(PosType), goecs.After(idA))
+	world.AddSystem(call("c", 0), goecs.Reads(VelType), goecs.After(idB))
+	_ = world.DisableSystem(idB)
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	if expect := []string{"a", "c"}; !reflect.DeepEqual(calls, expect) {
+		t.Fatalf("got %v, want %v", calls, expect)
+	}
+}
@@ -83,6 +83,7 @@ type slice struct {
 	grow     int
 	items    []item
 	size     int
+	free     []int // free slots, the last one will be the next to be used
 }
 
 type sliceIterator struct {
@@ -106,18 +107,13 @@ func (si *sliceIterator) Value() interface{} {
 }
 
 func (ss *slice) Add(ref interface{}) {
-	for i, si := range ss.items {
-		if !si.valid {
-			ss.items[i].ref = ref
-			ss.items[i].valid = true
-			ss.size++
-			return
-		}
+	if len(ss.free) == 0 {
+		ss.growCapacity()
 	}
-
-	ss.growCapacity()
-	ss.items[ss.size].ref = ref
-	ss.items[ss.size].valid = true
+	i := ss.free[len(ss.free)-1]
+	ss.free = ss.free[:len(ss.free)-1]
+	ss.items[i].ref = ref
+	ss.items[i].valid = true
 	ss.size++
 }
 
@@ -125,6 +121,7 @@ func (ss *slice) Remove(ref interface{}) error {
 	if i, err := ss.find(ref); err == nil {
 		ss.items[i].valid = false
 		ss.items[i].ref = nil
+		ss.free = append(ss.free, i)
 		ss.size--
 	} else {
 		return err
@@ -138,6 +135,7 @@ func (ss *slice) Clear() {
 		ss.items[i].ref = nil
 	}
 	ss.size = 0
+	ss.freeFrom(0)
 }
 
 func (ss slice) Size() int {
@@ -153,9 +151,24 @@ func (ss slice) Iterator() Iterator {
 }
 
 func (ss *slice) growCapacity() {
+	current := ss.capacity
 	ss.capacity += ss.grow
 	ss.items = append(ss.items, make([]item, ss.grow)...)
 	ss.grow = (ss.capacity >> 2) + 1 // next grow will be 25% + 1
+	// new slots go under the current free slots, so lower slots are used first
+	free := make([]int, 0, ss.capacity-current+len(ss.free))
+	for i := ss.capacity - 1; i >= current; i-- {
+		free = append(free, i)
+	}
+	ss.free = append(free, ss.free...)
+}
+
+// freeFrom set as free all the slots from the given one, lower slots will be used first
+func (ss *slice) freeFrom(slot int) {
+	ss.free = ss.free[:0]
+	for i := ss.capacity - 1; i >= slot; i-- {
+		ss.free = append(ss.free, i)
+	}
 }
 
 func (ss slice) find(ref interface{}) (int, error) {
@@ -182,6 +195,8 @@ func (ss *slice) Sort(less func(a, b interface{}) bool) {
 			return less(a.ref, b.ref)
 		}
 	})
+	// after sorting the free slots are the last ones
+	ss.freeFrom(ss.size)
 }
 
 // AssureCapacity grows the slice until the desired capacity is meet
@@ -212,6 +227,8 @@ func NewSlice(capacity int) Slice {
 		capacity: capacity,
 		grow:     capacity, // first grow will double capacity
 		size:     0,
+		free:     make([]int, 0, capacity),
 	}
+	slice.freeFrom(0)
 	return &slice
 }
@@ -410,3 +410,34 @@ func TestSlice_Grow(t *testing.T) {
 	expectCapacityGrow(t, sl, 251, 63)
 	expectSize(t, sl, 201)
 }
+
+func TestSlice_AddReuse(t *testing.T) {
+	sl := NewSlice(4).(*slice)
+
+	sl.Add(1)
+	sl.Add(2)
+	sl.Add(3)
+	sl.Add(4)
+
+	_ = sl.Remove(2)
+	_ = sl.Remove(4)
+
+	sl.Add(5)
+	sl.Add(6)
+
+	expectCapacityGrow(t, sl, 4, 4)
+	expectEquals(t, sl, []interface{}{1, 6, 3, 5})
+
+	sl.Clear()
+
+	sl.Add(7)
+	sl.Add(8)
+
+	expectEquals(t, sl, []interface{}{7, 8})
+
+	sl.AssureCapacity(10)
+
+	sl.Add(9)
+
+	expectEquals(t, sl, []interface{}{7, 8, 9})
+}
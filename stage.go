@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrStageNotFound is the error when we could not find a Stage
+	ErrStageNotFound = errors.New("stage not found")
+	// ErrStageExists is the error when we try to add a Stage that already exists
+	ErrStageExists = errors.New("stage already exists")
+)
+
+// Stage is a named group of System, on Update the stages run in order and within a Stage the systems run by priority
+type Stage string
+
+// Default stages, in the order that they run
+const (
+	PreUpdateStage   Stage = "PreUpdate"   // PreUpdateStage runs before FixedUpdateStage
+	FixedUpdateStage Stage = "FixedUpdate" // FixedUpdateStage runs at fixed rate, see World.SetFixedTimestep
+	UpdateStage      Stage = "Update"      // UpdateStage is the default Stage for a System
+	PostUpdateStage  Stage = "PostUpdate"  // PostUpdateStage runs after UpdateStage
+	RenderStage      Stage = "Render"      // RenderStage runs after PostUpdateStage
+)
+
+// Stages that does not run on each Update
+const (
+	StartupStage  Stage = "Startup"  // StartupStage runs once before the first Update, see World.AddStartupSystem
+	ShutdownStage Stage = "Shutdown" // ShutdownStage runs once on World.Close, see World.AddShutdownSystem
+)
+
+// runsOnce check if a Stage does not run on each Update
+func (s Stage) runsOnce() bool {
+	return s == StartupStage || s == ShutdownStage
+}
+
+// AddStageBefore adds a new Stage that runs before an existing one
+func (sys *Systems) AddStageBefore(stage, before Stage) error {
+	return sys.insertStage(stage, before, 0)
+}
+
+// AddStageAfter adds a new Stage that runs after an existing one
+func (sys *Systems) AddStageAfter(stage, after Stage) error {
+	return sys.insertStage(stage, after, 1)
+}
+
+// insertStage inserts a Stage in the position of an existing one plus an offset
+func (sys *Systems) insertStage(stage, existing Stage, offset int) error {
+	if sys.stageIndex(stage) != -1 {
+		return fmt.Errorf("%w: %s", ErrStageExists, stage)
+	}
+
+	i := sys.stageIndex(existing)
+	if i == -1 {
+		return fmt.Errorf("%w: %s", ErrStageNotFound, existing)
+	}
+
+	i += offset
+	sys.stages = append(sys.stages, "")
+	copy(sys.stages[i+1:], sys.stages[i:])
+	sys.stages[i] = stage
+	sys.dirty = true
+	return nil
+}
+
+// Stages returns the stages in the order that they run
+func (sys Systems) Stages() []Stage {
+	stages := make([]Stage, len(sys.stages))
+	copy(stages, sys.stages)
+	return stages
+}
+
+// stageIndex returns the position of a Stage, -1 if not found
+func (sys Systems) stageIndex(stage Stage) int {
+	for i, s := range sys.stages {
+		if s == stage {
+			return i
+		}
+	}
+	return -1
+}
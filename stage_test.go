@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2020 Juan Medina.
+ *
+ *  Permission is hereby granted, free of charge, to any person obtaining a copy
+ *  of this software and associated documentation files (the "Software"), to deal
+ *  in the Software without restriction, including without limitation the rights
+ *  to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ *  copies of the Software, and to permit persons to whom the Software is
+ *  furnished to do so, subject to the following conditions:
+ *
+ *  The above copyright notice and this permission notice shall be included in
+ *  all copies or substantial portions of the Software.
+ *
+ *  THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ *  IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ *  FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ *  AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ *  LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ *  OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ *  THE SOFTWARE.
+ */
+
+package goecs_test
+
+import (
+	"errors"
+	"github.com/juan-medina/goecs"
+	"reflect"
+	"testing"
+)
+
+func systemC(_ *goecs.World, _ float32) error {
+	systemCalls = append(systemCalls, "update c")
+	return nil
+}
+
+func TestWorld_Stages(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	world.AddSystem(systemA, goecs.InStage(goecs.RenderStage))
+	world.AddSystemWithPriority(systemB, 10, goecs.InStage(goecs.PostUpdateStage))
+	world.AddSystem(systemC, goecs.InStage(goecs.PreUpdateStage))
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	expect := []string{"update c", "update b", "update a"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_AddStage(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	const physics goecs.Stage = "Physics"
+	const ui goecs.Stage = "UI"
+
+	world.AddSystem(systemA, goecs.InStage(ui))
+	world.AddSystem(systemB)
+	world.AddSystem(systemC, goecs.InStage(physics))
+	world.AddListener(listenerA, dummySignalType)
+
+	world.Signal(dummySignal{})
+	if err := world.Update(0); !errors.Is(err, goecs.ErrStageNotFound) {
+		t.Fatalf("error on update got %v, want %v", err, goecs.ErrStageNotFound)
+	}
+
+	// it is reported once
+	world.Signal(dummySignal{})
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	// the systems in known stages and the listeners still run
+	if expect := []string{"update b", "notify a", "update b", "notify a"}; !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+	systemCalls = make([]string, 0)
+
+	if err := world.AddStageBefore(physics, goecs.UpdateStage); err != nil {
+		t.Fatalf("error on add stage got %v, want nil", err)
+	}
+	if err := world.AddStageAfter(ui, goecs.RenderStage); err != nil {
+		t.Fatalf("error on add stage got %v, want nil", err)
+	}
+
+	stages := []goecs.Stage{goecs.PreUpdateStage, goecs.FixedUpdateStage, physics, goecs.UpdateStage,
+		goecs.PostUpdateStage, goecs.RenderStage, ui}
+	if got := world.Stages(); !reflect.DeepEqual(got, stages) {
+		t.Fatalf("got %v, want %v", got, stages)
+	}
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	expect := []string{"update c", "update b", "update a"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	if err := world.AddStageAfter(ui, goecs.UpdateStage); !errors.Is(err, goecs.ErrStageExists) {
+		t.Fatalf("error on add stage got %v, want %v", err, goecs.ErrStageExists)
+	}
+	if err := world.AddStageBefore("Other", "Unknown"); !errors.Is(err, goecs.ErrStageNotFound) {
+		t.Fatalf("error on add stage got %v, want %v", err, goecs.ErrStageNotFound)
+	}
+}
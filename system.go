@@ -23,88 +23,534 @@
 package goecs
 
 import (
+	"errors"
+	"fmt"
 	"github.com/juan-medina/goecs/sparse"
 	"reflect"
 	"runtime"
 )
 
+var (
+	// ErrSystemNotFound is the error when we could not find a System registration
+	ErrSystemNotFound = errors.New("system not found")
+	// ErrSystemCycle is the error when the ordering constraints of the systems could not be satisfied
+	ErrSystemCycle = errors.New("system ordering cycle")
+)
+
 // System get invoke with Update() from a World
 type System func(world *World, delta float32) error
 
+// SystemID is the ID of a System registration
+type SystemID int64
+
+// SystemStatus is the status of a System registration
+type SystemStatus struct {
+	Name    string // Name of the System
+	Enabled bool   // Enabled is true if the System runs on Update
+	Skipped string // Skipped is the name of the RunCondition that skip the System on the last Update, if any
+}
+
+// SystemOption configures a System registration
+type SystemOption func(sr *systemRegistration)
+
+// InStage returns a SystemOption to register a System in a Stage, by default systems are registered in UpdateStage
+//
+// Systems in a Stage that is not found do not run, and the next Update returns ErrStageNotFound for them once
+func InStage(stage Stage) SystemOption {
+	return func(sr *systemRegistration) {
+		sr.stage = stage
+	}
+}
+
+// After returns a SystemOption to run a System after the systems with the given varg SystemID
+func After(ids ...SystemID) SystemOption {
+	return func(sr *systemRegistration) {
+		sr.after = append(sr.after, ids...)
+	}
+}
+
+// Before returns a SystemOption to run a System before the systems with the given varg SystemID
+func Before(ids ...SystemID) SystemOption {
+	return func(sr *systemRegistration) {
+		sr.before = append(sr.before, ids...)
+	}
+}
+
 // systemRegistration hold the registration of a system
 type systemRegistration struct {
-	system   System // system registered
-	priority int32  // priority for this system
-	id       int64  // this system id
+	system     System            // system registered
+	priority   int32             // priority for this system
+	id         SystemID          // this system id
+	stage      Stage             // stage of this system
+	after      []SystemID        // systems that should run before this one
+	before     []SystemID        // systems that should run after this one
+	reads      []ComponentType   // component types that this system reads
+	writes     []ComponentType   // component types that this system writes
+	declared   bool              // declared is true if this system declares the component types that access
+	conditions []RunCondition    // conditions that should be true for this system to run
+	skip       string            // skip is the name of the condition that skipped this system on the last Update
+	handler    ErrorHandler      // handler for the errors of this system
+	lastRun    uint64            // change tick of the last time that this system run
+	since      uint64            // changes after this tick are reported to the current run of this system
+	follows    map[SystemID]bool // systems that should run before this one in its stage, directly or through others
+	disabled   bool              // disabled systems do not run on Update
+	removed    bool              // removed systems do not run even if they are still in the order
 }
 
 // Systems manage registration of systems
 type Systems struct {
-	registrations      sparse.Slice // registrations of System
-	lastRegistrationID int64        // lastRegistrationID is the id of the last registration
+	registrations      sparse.Slice                     // registrations of System
+	lastRegistrationID SystemID                         // lastRegistrationID is the id of the last registration
+	lookup             map[SystemID]*systemRegistration // registrations by SystemID
+	stages             []Stage                          // stages in the order that they run
+	order              []*systemRegistration            // registrations in the order that they run
+	dirty              bool                             // dirty is true when the order needs to be sorted again
+	missing            error                            // error for the registrations in stages not found, if any
+	workers            int                              // workers to run systems in parallel
+	batch              []*systemRegistration            // batch of registrations that run together
+	fixedFrom          int                              // position in the order of the first fixed registration
+	fixedTo            int                              // position in the order after the last fixed registration
+	step               float32                          // step of the fixed timestep, 0 if is not enabled
+	maxSubSteps        int                              // maximum fixed steps on each Update
+	accumulator        float32                          // time accumulated for the fixed steps
+	frame              uint64                           // frame is the number of updates done
+	startup            []*systemRegistration            // registrations in StartupStage in the order that they run
+	shutdown           []*systemRegistration            // registrations in ShutdownStage in the order that they run
+	starting           bool                             // starting is true when startup registrations are pending
+	policy             ErrorPolicy                      // policy for the errors of the systems
+	recover            bool                             // recover from the panics of the systems
 }
 
-// Register adds a new registration with a given priority
-func (sys *Systems) Register(system System, priority int32) {
+// Register adds a new registration with a given priority and varg SystemOption, returning the SystemID of the
+// registration
+func (sys *Systems) Register(system System, priority int32, options ...SystemOption) SystemID {
 	// increment the id
 	sys.lastRegistrationID++
-	// add the registration
-	sys.registrations.Add(systemRegistration{
+	sr := &systemRegistration{
 		id:       sys.lastRegistrationID,
 		system:   system,
 		priority: priority,
-	})
+		stage:    UpdateStage,
+	}
+	for _, option := range options {
+		option(sr)
+	}
+	// add the registration
+	sys.registrations.Add(sr)
+	sys.lookup[sr.id] = sr
 	// keep the registration sorted
 	sys.registrations.Sort(sys.sortSystemByPriority)
+	sys.dirty = true
+	if sr.stage == StartupStage {
+		sys.starting = true
+	}
+	return sr.id
+}
+
+// Remove the registration with the given SystemID
+func (sys *Systems) Remove(id SystemID) error {
+	sr, ok := sys.lookup[id]
+	if !ok {
+		return ErrSystemNotFound
+	}
+	delete(sys.lookup, id)
+	sr.removed = true
+	sys.dirty = true
+	return sys.registrations.Remove(sr)
+}
+
+// Enable the registration with the given SystemID, so it will run on Update
+func (sys *Systems) Enable(id SystemID) error {
+	return sys.setDisabled(id, false)
+}
+
+// Disable the registration with the given SystemID, so it will not run on Update until is enabled again
+func (sys *Systems) Disable(id SystemID) error {
+	return sys.setDisabled(id, true)
+}
+
+// setDisabled set if a registration is disabled
+func (sys *Systems) setDisabled(id SystemID, disabled bool) error {
+	sr, ok := sys.lookup[id]
+	if !ok {
+		return ErrSystemNotFound
+	}
+	sr.disabled = disabled
+	return nil
+}
+
+// Status returns the SystemStatus of the registration with the given SystemID
+func (sys Systems) Status(id SystemID) (SystemStatus, error) {
+	sr, ok := sys.lookup[id]
+	if !ok {
+		return SystemStatus{}, ErrSystemNotFound
+	}
+	return SystemStatus{
+		Name:    systemName(sr.system),
+		Enabled: !sr.disabled,
+		Skipped: sr.skip,
+	}, nil
 }
 
 // sortSystemByPriority sorts by systemRegistration priority, if equal by id
 func (sys *Systems) sortSystemByPriority(a interface{}, b interface{}) bool {
-	first := a.(systemRegistration)
-	second := b.(systemRegistration)
+	first := a.(*systemRegistration)
+	second := b.(*systemRegistration)
 	if first.priority == second.priority {
 		return first.id < second.id
 	}
 	return first.priority > second.priority
 }
 
-// Update the systems
-func (sys *Systems) Update(world *World, delta float32) error {
-	var err error
-	// go trough al registrations
+// sort the registrations in the order that they run, stage by stage
+func (sys *Systems) sort() error {
+	if !sys.dirty {
+		return nil
+	}
+
+	// registrations are sorted by priority, keep that order in each stage
+	byStage := make(map[Stage][]*systemRegistration, len(sys.stages))
+	var missing []error
 	for it := sys.registrations.Iterator(); it != nil; it = it.Next() {
-		// get the value
-		sr := it.Value().(systemRegistration)
-		//invoke the system, if error return
-		if err = sr.system(world, delta); err != nil {
+		sr := it.Value().(*systemRegistration)
+		// registrations in stages not found does not run, but the others do
+		if sys.stageIndex(sr.stage) == -1 && !sr.stage.runsOnce() {
+			missing = append(missing, fmt.Errorf("%w: %s for system %s", ErrStageNotFound, sr.stage,
+				systemName(sr.system)))
+			continue
+		}
+		byStage[sr.stage] = append(byStage[sr.stage], sr)
+	}
+
+	order := make([]*systemRegistration, 0, sys.registrations.Size())
+	for _, stage := range sys.stages {
+		sorted, err := sys.sortStage(byStage[stage])
+		if err != nil {
 			return err
 		}
+		if stage == FixedUpdateStage {
+			sys.fixedFrom = len(order)
+			sys.fixedTo = len(order) + len(sorted)
+		}
+		order = append(order, sorted...)
+	}
+
+	var err error
+	if sys.startup, err = sys.sortStage(byStage[StartupStage]); err != nil {
+		return err
+	}
+	if sys.shutdown, err = sys.sortStage(byStage[ShutdownStage]); err != nil {
+		return err
 	}
+
+	sys.order = order
+	sys.missing = joinErrors(missing...)
+	sys.dirty = false
 	return nil
 }
 
+// sortStage sorts the registrations of a stage using their ordering constraints, the registrations should be
+// sorted by priority that is used to break ties
+func (sys *Systems) sortStage(list []*systemRegistration) ([]*systemRegistration, error) {
+	// constraints within the stage, as registrations that should run after a registration
+	next := make(map[*systemRegistration][]*systemRegistration)
+	pending := make(map[*systemRegistration]int, len(list))
+	constraint := func(first, second *systemRegistration) {
+		next[first] = append(next[first], second)
+		pending[second]++
+	}
+
+	for _, sr := range list {
+		for _, id := range sr.after {
+			if other, err := sys.constrained(sr, id, -1); err != nil {
+				return nil, err
+			} else if other != nil {
+				constraint(other, sr)
+			}
+		}
+		for _, id := range sr.before {
+			if other, err := sys.constrained(sr, id, 1); err != nil {
+				return nil, err
+			} else if other != nil {
+				constraint(sr, other)
+			}
+		}
+	}
+
+	sorted := make([]*systemRegistration, 0, len(list))
+	placed := make(map[*systemRegistration]bool, len(list))
+	for len(sorted) < len(list) {
+		// pick the first registration, by priority, without pending constraints
+		var ready *systemRegistration
+		for _, sr := range list {
+			if !placed[sr] && pending[sr] == 0 {
+				ready = sr
+				break
+			}
+		}
+		if ready == nil {
+			names := ""
+			for _, sr := range list {
+				if !placed[sr] {
+					if names != "" {
+						names += ","
+					}
+					names += systemName(sr.system)
+				}
+			}
+			return nil, fmt.Errorf("%w: %s", ErrSystemCycle, names)
+		}
+		placed[ready] = true
+		sorted = append(sorted, ready)
+		for _, sr := range next[ready] {
+			pending[sr]--
+		}
+	}
+
+	// registrations that should run before each one, in sorted order the ones before are already known
+	for _, sr := range sorted {
+		sr.follows = make(map[SystemID]bool)
+	}
+	for _, sr := range sorted {
+		for _, other := range next[sr] {
+			other.follows[sr.id] = true
+			for id := range sr.follows {
+				other.follows[id] = true
+			}
+		}
+	}
+
+	return sorted, nil
+}
+
+// constrained returns the registration with the given SystemID if it is in the same stage of a registration, if
+// it is in other stage the direction, -1 for before and 1 for after, should match the stages order
+func (sys *Systems) constrained(sr *systemRegistration, id SystemID, direction int) (*systemRegistration, error) {
+	other, ok := sys.lookup[id]
+	// constraints with removed systems are ignored
+	if !ok {
+		return nil, nil
+	}
+	if other.stage == sr.stage {
+		return other, nil
+	}
+	// stages that run once are not ordered with the rest
+	if other.stage.runsOnce() || sr.stage.runsOnce() {
+		return nil, nil
+	}
+	if diff := sys.stageIndex(other.stage) - sys.stageIndex(sr.stage); diff*direction < 0 {
+		return nil, fmt.Errorf("%w: %s in stage %s, %s in stage %s", ErrSystemCycle,
+			systemName(sr.system), sr.stage, systemName(other.system), other.stage)
+	}
+	return nil, nil
+}
+
+// Update the systems
+//
+// Each system run with a new change tick, and the changes reported to it are the ones after it last run
+func (sys *Systems) Update(world *World, delta float32) error {
+	missing, err := sys.update(world, delta)
+	return joinErrors(missing, err)
+}
+
+// update the systems, returning apart the error for the registrations in stages not found, that is reported once
+// after the registrations are sorted and does not stop the Update
+func (sys *Systems) update(world *World, delta float32) (missing error, err error) {
+	if err = sys.sort(); err != nil {
+		return nil, err
+	}
+	missing, sys.missing = sys.missing, nil
+
+	view := world.View
+	// changes done after the systems are newer than any system run
+	defer func() {
+		view.since = 0
+		view.advance()
+		sys.frame++
+	}()
+
+	var errs []error
+	if sys.starting {
+		if err := sys.start(world); sys.stop(world, &errs, err) {
+			return missing, joinErrors(errs...)
+		}
+	}
+
+	if err := sys.run(world, delta, sys.order[:sys.fixedFrom]); sys.stop(world, &errs, err) {
+		return missing, joinErrors(errs...)
+	}
+
+	// without fixed timestep the fixed systems run once with the frame delta
+	steps, fixedDelta := 1, delta
+	if sys.step > 0 {
+		steps, fixedDelta = sys.fixedSteps(delta), sys.step
+	}
+	for i := 0; i < steps; i++ {
+		if err := sys.run(world, fixedDelta, sys.order[sys.fixedFrom:sys.fixedTo]); sys.stop(world, &errs, err) {
+			return missing, joinErrors(errs...)
+		}
+	}
+
+	errs = append(errs, sys.run(world, delta, sys.order[sys.fixedTo:]))
+	return missing, joinErrors(errs...)
+}
+
+// stop collects an error, returning true if the Update should stop because of the policy or because the context
+// of the Update is done
+func (sys *Systems) stop(world *World, errs *[]error, err error) bool {
+	if sys.policy.collect(errs, err) {
+		return true
+	}
+	if ctxErr := world.Context().Err(); ctxErr != nil {
+		if !errors.Is(err, ctxErr) {
+			*errs = append(*errs, ctxErr)
+		}
+		return true
+	}
+	return false
+}
+
+// start runs the startup registrations that has not run yet
+func (sys *Systems) start(world *World) error {
+	pending := make([]*systemRegistration, 0, len(sys.startup))
+	for _, sr := range sys.startup {
+		if sr.lastRun == 0 {
+			pending = append(pending, sr)
+		}
+	}
+	err := sys.run(world, 0, pending)
+	// disabled or skipped registrations keep pending
+	sys.starting = false
+	for _, sr := range pending {
+		sys.starting = sys.starting || sr.lastRun == 0
+	}
+	return err
+}
+
+// Shutdown runs the shutdown systems
+func (sys *Systems) Shutdown(world *World) error {
+	if err := sys.sort(); err != nil {
+		return err
+	}
+
+	view := world.View
+	defer func() {
+		view.since = 0
+		view.advance()
+	}()
+
+	return sys.run(world, 0, sys.shutdown)
+}
+
+// run the given registrations in order
+func (sys *Systems) run(world *World, delta float32, order []*systemRegistration) error {
+	view := world.View
+
+	var errs []error
+	// go trough al registrations in order, in batches that could run in parallel
+	for start := 0; start < len(order); {
+		// stop if the context of the Update is done
+		if err := world.Context().Err(); err != nil {
+			return joinErrors(append(errs, err)...)
+		}
+		var batch []*systemRegistration
+		batch, start = sys.nextBatch(world, order, start)
+		if len(batch) == 0 {
+			continue
+		}
+		// each system get the changes since it last run, the View report the ones since the oldest
+		view.since = batch[0].lastRun
+		tick := view.advance()
+		for _, sr := range batch {
+			if sr.lastRun < view.since {
+				view.since = sr.lastRun
+			}
+			sr.since = sr.lastRun
+			sr.lastRun = tick
+		}
+		//invoke the systems, if error return depending on the policy
+		var err error
+		if len(batch) == 1 {
+			err = sys.invoke(world, delta, batch[0])
+		} else {
+			err = sys.runParallel(world, delta, batch)
+		}
+		if sys.policy.collect(&errs, err) {
+			return err
+		}
+	}
+	return joinErrors(errs...)
+}
+
+// invoke the system of a registration, wrapping its error in a SystemError that is passed to its handler, if any
+func (sys *Systems) invoke(world *World, delta float32, sr *systemRegistration) error {
+	var err error
+	if sys.recover {
+		err = protect(world.View, systemName(sr.system), func() error {
+			return sr.system(world, delta)
+		})
+	} else {
+		err = sr.system(world, delta)
+	}
+	if err == nil {
+		return nil
+	}
+	err = &SystemError{ID: sr.id, Name: systemName(sr.system), Err: err}
+	if sr.handler != nil {
+		err = sr.handler(world, err)
+	}
+	return err
+}
+
+// SetErrorPolicy sets the ErrorPolicy for the errors of the systems
+func (sys *Systems) SetErrorPolicy(policy ErrorPolicy) {
+	sys.policy = policy
+}
+
+// SetRecover sets if the panics of the systems are converted into a *PanicError
+func (sys *Systems) SetRecover(enabled bool) {
+	sys.recover = enabled
+}
+
+// Frame returns the number of updates done
+func (sys Systems) Frame() uint64 {
+	return sys.frame
+}
+
 // Clear the systems
 func (sys *Systems) Clear() {
 	sys.registrations.Clear()
+	for id, sr := range sys.lookup {
+		sr.removed = true
+		delete(sys.lookup, id)
+	}
+	sys.dirty = true
 }
 
 // String returns the string representation of the systems
 func (sys Systems) String() string {
 	str := ""
 	for it := sys.registrations.Iterator(); it != nil; it = it.Next() {
-		l := it.Value().(systemRegistration)
+		l := it.Value().(*systemRegistration)
 		if str != "" {
 			str += ","
 		}
-		name := runtime.FuncForPC(reflect.ValueOf(l.system).Pointer()).Name()
-		str += name
+		str += systemName(l.system)
 	}
 	return str
 }
 
+// systemName returns the name of the function of a System
+func systemName(system System) string {
+	return runtime.FuncForPC(reflect.ValueOf(system).Pointer()).Name()
+}
+
 // NewSystems creates a new Systems
 func NewSystems(systems int) *Systems {
 	return &Systems{
 		registrations: sparse.NewSlice(systems),
+		lookup:        make(map[SystemID]*systemRegistration),
+		stages:        []Stage{PreUpdateStage, FixedUpdateStage, UpdateStage, PostUpdateStage, RenderStage},
+		order:         make([]*systemRegistration, 0, systems),
 	}
 }
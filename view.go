@@ -23,9 +23,11 @@
 package goecs
 
 import (
+	"container/heap"
 	"errors"
 	"fmt"
 	"sort"
+	"sync/atomic"
 )
 
 var (
@@ -34,123 +36,251 @@ var (
 )
 
 // View represent a set of Entity objects
+//
+// Entities are stored in archetypes, entities that has the same set of ComponentType, so a view.Iterator only need
+// to go trough the archetypes that match its filter
 type View struct {
-	capacity int
-	grow     int
-	items    []*Entity
-	size     int
-	lastID   EntityID
-	lookup   map[EntityID]int
+	capacity   int
+	grow       int
+	items      []*Entity
+	size       int
+	records    []entityRecord        // records of each EntityID index
+	freeIDs    []uint32              // free EntityID indexes, the last one will be the next to be used
+	free       []int                 // free slots, the last one will be the next to be used
+	archetypes []*archetype          // archetypes in this View
+	index      map[string]*archetype // archetypes by key
+	queries    []*Query              // registered queries
+	version    int                   // version changes every time that the archetypes are modified
+	tick       uint64                // current change tick
+	since      uint64                // changes after this tick are reported by the Added, Changed and Removed Term
+	lifecycle  lifecycle             // receives the lifecycle signals, if any
+	tracking   bool                  // tracking is true when the View records the last Entity iterated
+	last       uint64                // EntityID of the last Entity iterated, when tracking
+	parallel   int32                 // parallel is not 0 when systems are running in parallel
+}
+
+// entityRecord hold the generation and slot for an EntityID index
+type entityRecord struct {
+	generation uint32 // current generation of the index
+	slot       int    // slot of the Entity with this index, -1 if the index is free
 }
 
 // Iterator allow to iterate trough the View
 type Iterator struct {
-	data    *View
-	current int
-	filter  []ComponentType
+	data       *View
+	filter     Filter
+	query      *Query       // registered Query that this Iterator use, if any
+	archetypes []*archetype // archetypes that match the filter
+	heads      heads        // next Entity of each archetype with entities left, as a heap by slot
+	known      int          // number of archetypes in the View that we have checked
+	version    int          // View version for the current cursors
+	current    *Entity      // current Entity
+	slot       int          // slot of the current Entity
+	since      uint64       // changes after this tick are reported by the Filter
 }
 
 // Next return a Iterator to the next Entity
 func (ei *Iterator) Next() *Iterator {
-	for i := ei.current + 1; i < len(ei.data.items); i++ {
-		item := ei.data.items[i]
-		if item != nil {
-			if !item.IsEmpty() {
-				if item.Contains(ei.filter...) {
-					ei.current = i
-					return ei
-				}
+	// if the View has change we need to find again our position
+	if ei.version != ei.data.version {
+		ei.seek()
+	}
+
+	for {
+		// get the Entity with the lowest slot from the archetypes
+		if len(ei.heads) == 0 {
+			return nil
+		}
+
+		head := &ei.heads[0]
+		ent := head.arch.at(head.cursor)
+		ei.slot = ent.slot
+		head.cursor.row++
+		if head.arch.skip(&head.cursor) {
+			head.slot = head.arch.at(head.cursor).slot
+			heap.Fix(&ei.heads, 0)
+		} else {
+			heap.Pop(&ei.heads)
+		}
+
+		if ei.filter.accepts(ent, ei.since) {
+			ei.current = ent
+			if ei.data.tracking && !ei.data.shared() {
+				atomic.StoreUint64(&ei.data.last, uint64(ent.id))
 			}
+			return ei
 		}
 	}
-	return nil
 }
 
-// first return a Iterator to the first Entity
-func (ei *Iterator) first() *Iterator {
-	for i := ei.current + 1; i < len(ei.data.items); i++ {
-		item := ei.data.items[i]
-		if item != nil {
-			if !item.IsEmpty() {
-				if item.Contains(ei.filter...) {
-					ei.current = i
-					return ei
-				}
+// seek find the matching archetypes and the position in them after the current slot
+func (ei *Iterator) seek() {
+	if ei.query != nil {
+		// the registered Query already knows the matching archetypes
+		for len(ei.archetypes) < len(ei.query.archetypes) {
+			ei.archetypes = append(ei.archetypes, ei.query.archetypes[len(ei.archetypes)])
+		}
+	} else {
+		for ; ei.known < len(ei.data.archetypes); ei.known++ {
+			if arch := ei.data.archetypes[ei.known]; ei.filter.matches(arch) {
+				ei.archetypes = append(ei.archetypes, arch)
 			}
 		}
 	}
-	return nil
+	ei.heads = ei.heads[:0]
+	for _, arch := range ei.archetypes {
+		if c := arch.seek(ei.slot); arch.skip(&c) {
+			ei.heads = append(ei.heads, head{arch: arch, cursor: c, slot: arch.at(c).slot})
+		}
+	}
+	heap.Init(&ei.heads)
+	ei.version = ei.data.version
+}
+
+// head is the next Entity of an archetype in an Iterator
+type head struct {
+	arch   *archetype // archetype of the Entity
+	cursor cursor     // position of the Entity
+	slot   int        // slot of the Entity
+}
+
+// heads is a heap of head sorted by slot
+type heads []head
+
+// Len returns the number of head
+func (h heads) Len() int {
+	return len(h)
+}
+
+// Less check if a head has a lower slot than other
+func (h heads) Less(i, j int) bool {
+	return h[i].slot < h[j].slot
+}
+
+// Swap two head
+func (h heads) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+// Push adds a head
+func (h *heads) Push(x interface{}) {
+	*h = append(*h, x.(head))
+}
+
+// Pop removes the last head
+func (h *heads) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
 }
 
 // Value returns the value of the current Iterator
 func (ei *Iterator) Value() *Entity {
-	return ei.data.items[ei.current]
+	return ei.current
 }
 
 // First return the first EntityID that match the given ComponentType
 func (v *View) First(components ...ComponentType) (EntityID, error) {
-	for _, si := range v.items {
-		if si != nil {
-			if !si.IsEmpty() {
-				if si.Contains(components...) {
-					return si.ID(), nil
-				}
-			}
-		}
+	if it := v.Iterator(components...); it != nil {
+		return it.Value().ID(), nil
 	}
 	return 0, ErrEntityNotFound
 }
 
 // AddEntity a Entity instance to a View given it components
 func (v *View) AddEntity(data ...Component) EntityID {
-	v.lastID++
-	for i, si := range v.items {
-		if si != nil {
-			if si.IsEmpty() {
-				si.Reuse(v.lastID, data...)
-				v.size++
-				v.lookup[v.lastID] = i
-				return v.lastID
-			}
-		} else {
-			v.items[i] = NewEntity(v.lastID, data...)
-			v.size++
-			v.lookup[v.lastID] = i
-			return v.lastID
-		}
+	if len(v.free) == 0 {
+		v.growCapacity()
 	}
+	slot := v.free[len(v.free)-1]
+	v.free = v.free[:len(v.free)-1]
+	return v.place(slot, data...)
+}
 
-	v.growCapacity()
-	v.items[v.size] = NewEntity(v.lastID, data...)
-	v.lookup[v.lastID] = v.size
+// place a new Entity in the given slot
+func (v *View) place(slot int, data ...Component) EntityID {
+	id := v.newID(slot)
+	if v.items[slot] == nil {
+		v.items[slot] = NewEntity(id, data...)
+	} else {
+		v.items[slot].Reuse(id, data...)
+	}
+	v.attach(v.items[slot], slot)
 	v.size++
-	return v.lastID
+	v.created(v.items[slot])
+	return id
+}
+
+// newID returns a new EntityID for an Entity in the given slot, reusing a free index with a new generation
+func (v *View) newID(slot int) EntityID {
+	if last := len(v.freeIDs) - 1; last >= 0 {
+		index := v.freeIDs[last]
+		v.freeIDs = v.freeIDs[:last]
+		rec := &v.records[index]
+		rec.generation++
+		rec.slot = slot
+		return newEntityID(index, rec.generation)
+	}
+	v.records = append(v.records, entityRecord{slot: slot})
+	return newEntityID(uint32(len(v.records)-1), 0)
+}
+
+// locate returns the slot of the Entity with the given EntityID
+func (v View) locate(id EntityID) (int, bool) {
+	index := id.Index()
+	if index == 0 || int(index) >= len(v.records) {
+		return 0, false
+	}
+	rec := v.records[index]
+	if rec.slot < 0 || rec.generation != id.Generation() {
+		return 0, false
+	}
+	return rec.slot, true
 }
 
 // Remove a Entity from a View
 func (v *View) Remove(id EntityID) error {
-	if i, err := v.find(id); err == nil {
-		v.items[i].Clear()
-		v.size--
-	} else {
-		return err
+	if slot, ok := v.locate(id); ok {
+		v.items[slot].Clear()
+		return nil
 	}
-	return nil
+	return ErrEntityNotFound
 }
 
-// Get a Entity from a View giving it EntityID
-func (v *View) Get(id EntityID) *Entity {
-	return v.items[v.lookup[id]]
+// Get a Entity from a View giving it EntityID, return ErrEntityNotFound if the Entity has been removed
+func (v *View) Get(id EntityID) (*Entity, error) {
+	if slot, ok := v.locate(id); ok {
+		return v.items[slot], nil
+	}
+	return nil, ErrEntityNotFound
+}
+
+// Has check that the View has an Entity with the given EntityID
+func (v *View) Has(id EntityID) bool {
+	_, ok := v.locate(id)
+	return ok
 }
 
 // Clear removes all Entity from the View
 func (v *View) Clear() {
-	for i := 0; i < v.capacity; i++ {
-		if v.items[i] != nil {
-			v.items[i].Clear()
-		}
+	for _, arch := range v.archetypes {
+		arch.each(func(ent *Entity) {
+			v.destroyed(ent)
+			ent.arch = nil
+			ent.block = nil
+			ent.Clear()
+		})
+		arch.clear()
+	}
+	v.freeIDs = v.freeIDs[:0]
+	for index := len(v.records) - 1; index > 0; index-- {
+		v.records[index].slot = -1
+		v.freeIDs = append(v.freeIDs, uint32(index))
 	}
 	v.size = 0
+	v.freeFrom(0)
+	v.version++
 }
 
 // Size is the number of Entity in this View
@@ -160,33 +290,139 @@ func (v View) Size() int {
 
 // Iterator return an view.Iterator for the given varg ComponentType
 func (v *View) Iterator(types ...ComponentType) *Iterator {
+	return v.Filter(With(types...))
+}
+
+// Filter return an view.Iterator for the entities that match the given varg Term
+//
+//	for it := world.Filter(goecs.With(PosType, VelType), goecs.Without(FrozenType)); it != nil; it = it.Next() {
+//		// ...
+//	}
+func (v *View) Filter(terms ...Term) *Iterator {
+	return v.filter(v.since, terms...)
+}
+
+// filter return an view.Iterator for the entities that match the given varg Term, reporting the changes after the
+// given tick
+func (v *View) filter(since uint64, terms ...Term) *Iterator {
 	it := Iterator{
 		data:    v,
-		current: -1,
-		filter:  types,
+		filter:  NewFilter(terms...),
+		slot:    -1,
+		version: -1,
+		since:   since,
 	}
-	return it.first()
+	return it.Next()
 }
 
 // growCapacity increases the View capacity
 func (v *View) growCapacity() {
+	current := v.capacity
 	v.capacity += v.grow
 	v.items = append(v.items, make([]*Entity, v.grow)...)
 	v.grow = (v.capacity >> 2) + 1 // next grow will be 25% + 1
+	// new slots go under the current free slots, so lower slots are used first
+	free := make([]int, 0, v.capacity-current+len(v.free))
+	for i := v.capacity - 1; i >= current; i-- {
+		free = append(free, i)
+	}
+	v.free = append(free, v.free...)
 }
 
-// find a Entity position in a View giving it EntityID
-func (v View) find(id EntityID) (int, error) {
-	for i, si := range v.items {
-		if si != nil {
-			if !si.IsEmpty() {
-				if si.ID() == id {
-					return i, nil
-				}
+// freeFrom set as free all the slots from the given one, lower slots will be used first
+func (v *View) freeFrom(slot int) {
+	v.free = v.free[:0]
+	for i := v.capacity - 1; i >= slot; i-- {
+		v.free = append(v.free, i)
+	}
+}
+
+// archetype returns the archetype for a sorted set of ComponentType, creating it if needed
+func (v *View) archetype(types []ComponentType) *archetype {
+	key := archetypeKey(types)
+	if arch, ok := v.index[key]; ok {
+		return arch
+	}
+	arch := newArchetype(v, types)
+	v.index[key] = arch
+	v.archetypes = append(v.archetypes, arch)
+	for _, q := range v.queries {
+		if q.filter.matches(arch) {
+			q.archetypes = append(q.archetypes, arch)
+		}
+	}
+	return arch
+}
+
+// edge returns the archetype that we reach adding or removing a ComponentType from a given archetype
+func (v *View) edge(from *archetype, ctype ComponentType) *archetype {
+	if to, ok := from.edges[ctype]; ok {
+		return to
+	}
+
+	types := make([]ComponentType, 0, len(from.types)+1)
+	if from.has(ctype) {
+		for _, t := range from.types {
+			if t != ctype {
+				types = append(types, t)
 			}
 		}
+	} else {
+		types = append(types, from.types...)
+		types = append(types, ctype)
+		sort.Slice(types, func(i, j int) bool {
+			return types[i] < types[j]
+		})
 	}
-	return 0, ErrEntityNotFound
+
+	to := v.archetype(types)
+	from.edges[ctype] = to
+	to.edges[ctype] = from
+	return to
+}
+
+// attach an Entity in a given slot to the archetype for it components
+func (v *View) attach(ent *Entity, slot int) {
+	types := make([]ComponentType, 0, len(ent.components))
+	for t := range ent.components {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+	for _, data := range ent.components {
+		data.added = v.tick
+		data.changed = v.tick
+	}
+	ent.slot = slot
+	v.archetype(types).insert(ent)
+	v.version++
+}
+
+// advance the change tick, returning the new tick
+func (v *View) advance() uint64 {
+	v.tick++
+	return v.tick
+}
+
+// migrate an Entity to the archetype that we reach adding or removing the given ComponentType
+func (v *View) migrate(ent *Entity, ctype ComponentType) {
+	to := v.edge(ent.arch, ctype)
+	ent.arch.remove(ent)
+	to.insert(ent)
+	v.version++
+}
+
+// release an Entity from it archetype and free it EntityID index
+func (v *View) release(ent *Entity) {
+	v.destroyed(ent)
+	index := ent.id.Index()
+	v.records[index].slot = -1
+	v.freeIDs = append(v.freeIDs, index)
+	ent.arch.remove(ent)
+	v.free = append(v.free, ent.slot)
+	v.size--
+	v.version++
 }
 
 // Sort the entities in place with a less function
@@ -196,24 +432,32 @@ func (v *View) Sort(less func(a, b *Entity) bool) {
 		b := v.items[j]
 		if a == nil {
 			return false
-		} else if a.IsEmpty() {
+		} else if a.arch == nil {
 			return false
 		} else if b == nil {
 			return true
-		} else if b.IsEmpty() {
+		} else if b.arch == nil {
 			return true
 		} else {
 			return less(a, b)
 		}
 	})
-	// update index in lookup
+	// update slots in the records
 	for i, si := range v.items {
 		if si != nil {
-			if !si.IsEmpty() {
-				v.lookup[si.ID()] = i
+			si.slot = i
+			if si.arch != nil {
+				v.records[si.id.Index()].slot = i
 			}
 		}
 	}
+	// keep archetypes in View order
+	for _, arch := range v.archetypes {
+		arch.sort()
+	}
+	// after sorting the free slots are the last ones
+	v.freeFrom(v.size)
+	v.version++
 }
 
 // String get a string representation of a View
@@ -233,11 +477,18 @@ func (v View) String() string {
 // NewView creates a new empty View with a given capacity
 func NewView(capacity int) *View {
 	slice := View{
-		items:    make([]*Entity, capacity),
-		capacity: capacity,
-		grow:     capacity, // first grow will double capacity
-		size:     0,
-		lookup:   make(map[EntityID]int),
+		items:      make([]*Entity, capacity),
+		capacity:   capacity,
+		grow:       capacity, // first grow will double capacity
+		size:       0,
+		records:    []entityRecord{{slot: -1}}, // index 0 is never used so EntityID 0 is not valid
+		freeIDs:    make([]uint32, 0),
+		free:       make([]int, 0, capacity),
+		archetypes: make([]*archetype, 0),
+		index:      make(map[string]*archetype),
+		queries:    make([]*Query, 0),
+		tick:       1, // changes before running any System are after tick 0
 	}
+	slice.freeFrom(0)
 	return &slice
 }
@@ -302,7 +302,11 @@ func TestView_Get(t *testing.T) {
 	view := goecs.NewView(2)
 	id := view.AddEntity(Pos{X: 3, Y: -3}, Vel{X: 4, Y: 4})
 
-	ent := view.Get(id)
+	ent, err := view.Get(id)
+
+	if err != nil {
+		t.Fatalf("error on get got %v, want nil", err)
+	}
 
 	if ent.ID() != id {
 		t.Fatalf("error on get got id %d, expect id %d", ent.ID(), id)
@@ -381,15 +385,190 @@ func TestView_GetWithSort(t *testing.T) {
 		{X: 3, Y: -3},
 	})
 
-	got := view.Get(id1)
+	got, _ := view.Get(id1)
 
 	if got.ID() != id1 {
 		t.Fatalf("error on get after sort got id %v, expect id %v", got.ID(), id1)
 	}
 
-	got = view.Get(id2)
+	got, _ = view.Get(id2)
 
 	if got.ID() != id2 {
 		t.Fatalf("error on get after sort got id %v, expect id %v", got.ID(), id2)
 	}
 }
+
+func TestView_IteratorWithChanges(t *testing.T) {
+	view := goecs.NewView(2)
+
+	view.AddEntity(Pos{X: 0, Y: 0}, Vel{X: 1, Y: 1})
+	view.AddEntity(Pos{X: 1, Y: 1})
+	view.AddEntity(Pos{X: 2, Y: 2}, Vel{X: 1, Y: 1})
+	view.AddEntity(Pos{X: 3, Y: 3}, Vel{X: 1, Y: 1})
+
+	visited := make([]Pos, 0)
+	for it := view.Iterator(PosType); it != nil; it = it.Next() {
+		ent := it.Value()
+		visited = append(visited, ent.Get(PosType).(Pos))
+		if ent.Contains(VelType) {
+			ent.Remove(VelType)
+		} else {
+			ent.Add(Vel{X: 2, Y: 2})
+		}
+	}
+
+	want := []Pos{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}}
+	if !reflect.DeepEqual(visited, want) {
+		t.Fatalf("got %v, want %v", visited, want)
+	}
+
+	got := make([]goecs.EntityID, 0)
+	for it := view.Iterator(VelType); it != nil; it = it.Next() {
+		got = append(got, it.Value().ID())
+	}
+
+	expect := []goecs.EntityID{2}
+	if !entitiesEqual(got, expect) {
+		t.Fatalf("got %v, want %v", got, expect)
+	}
+}
+
+func TestView_IteratorWithComposition(t *testing.T) {
+	view := goecs.NewView(5)
+	ids := make([]goecs.EntityID, 0)
+	for i := 0; i < 5; i++ {
+		ids = append(ids, view.AddEntity(Pos{X: float32(i), Y: float32(i)}))
+	}
+
+	// entities that change their components before we reach them are still visited
+	got := make([]Pos, 0)
+	for it := view.Iterator(PosType); it != nil; it = it.Next() {
+		got = append(got, it.Value().Get(PosType).(Pos))
+		if len(got) == 2 {
+			ent, _ := view.Get(ids[3])
+			ent.Add(Vel{X: 1, Y: 1})
+		}
+	}
+
+	want := []Pos{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}, {X: 4, Y: 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// and they keep their order after a sort
+	view.Sort(func(a, b *goecs.Entity) bool {
+		return a.Get(PosType).(Pos).X > b.Get(PosType).(Pos).X
+	})
+	ent, _ := view.Get(ids[4])
+	ent.Add(Vel{X: 1, Y: 1})
+	ent, _ = view.Get(ids[3])
+	ent.Remove(VelType)
+
+	expectViewPositions(t, view, []Pos{{X: 4, Y: 4}, {X: 3, Y: 3}, {X: 2, Y: 2}, {X: 1, Y: 1}, {X: 0, Y: 0}})
+}
+
+func TestView_Has(t *testing.T) {
+	view := goecs.NewView(2)
+	id1 := view.AddEntity(Pos{X: 3, Y: -3})
+	id2 := view.AddEntity(Pos{X: 0, Y: 0})
+
+	if !view.Has(id1) || !view.Has(id2) {
+		t.Fatalf("error on has, expect to have %v and %v", id1, id2)
+	}
+
+	_ = view.Remove(id1)
+
+	if view.Has(id1) {
+		t.Fatalf("error on has, expect to not have %v", id1)
+	}
+
+	if _, err := view.Get(id1); !errors.Is(err, goecs.ErrEntityNotFound) {
+		t.Fatalf("error on get removed entity got %v, want %v", err, goecs.ErrEntityNotFound)
+	}
+
+	ent, _ := view.Get(id2)
+	ent.Clear()
+
+	if view.Has(id2) {
+		t.Fatalf("error on has, expect to not have %v", id2)
+	}
+
+	got := view.Size()
+	expect := 0
+
+	if got != expect {
+		t.Fatalf("error on view size got %d, want %d", got, expect)
+	}
+}
+
+func TestView_AddReuse(t *testing.T) {
+	view := goecs.NewView(4)
+	view.AddEntity(Pos{X: 1, Y: 1})
+	id2 := view.AddEntity(Pos{X: 2, Y: 2})
+	view.AddEntity(Pos{X: 3, Y: 3})
+	id4 := view.AddEntity(Pos{X: 4, Y: 4})
+
+	_ = view.Remove(id2)
+	_ = view.Remove(id4)
+
+	view.AddEntity(Pos{X: 5, Y: 5})
+	view.AddEntity(Pos{X: 6, Y: 6})
+
+	expectViewPositions(t, view, []Pos{
+		{X: 1, Y: 1},
+		{X: 6, Y: 6},
+		{X: 3, Y: 3},
+		{X: 5, Y: 5},
+	})
+
+	view.Clear()
+
+	view.AddEntity(Pos{X: 7, Y: 7})
+	view.AddEntity(Pos{X: 8, Y: 8})
+
+	expectViewPositions(t, view, []Pos{
+		{X: 7, Y: 7},
+		{X: 8, Y: 8},
+	})
+}
+
+func TestView_GetStale(t *testing.T) {
+	view := goecs.NewView(2)
+	target := view.AddEntity(Pos{X: 1, Y: 1})
+	other := view.AddEntity(Pos{X: 2, Y: 2})
+
+	_ = view.Remove(target)
+
+	// the new entity reuse the index of the removed one
+	reused := view.AddEntity(Pos{X: 3, Y: 3})
+
+	if reused.Index() != target.Index() {
+		t.Fatalf("error on reuse got index %d, want %d", reused.Index(), target.Index())
+	}
+
+	if reused.Generation() != target.Generation()+1 {
+		t.Fatalf("error on reuse got generation %d, want %d", reused.Generation(), target.Generation()+1)
+	}
+
+	if _, err := view.Get(target); !errors.Is(err, goecs.ErrEntityNotFound) {
+		t.Fatalf("error on get stale entity got %v, want %v", err, goecs.ErrEntityNotFound)
+	}
+
+	if view.Has(target) {
+		t.Fatalf("error on has, expect to not have stale entity %v", target)
+	}
+
+	if err := view.Remove(target); !errors.Is(err, goecs.ErrEntityNotFound) {
+		t.Fatalf("error on remove stale entity got %v, want %v", err, goecs.ErrEntityNotFound)
+	}
+
+	for _, id := range []goecs.EntityID{other, reused} {
+		if ent, err := view.Get(id); err != nil || ent.ID() != id {
+			t.Fatalf("error on get %v got %v, %v", id, ent, err)
+		}
+	}
+
+	if _, err := view.Get(0); !errors.Is(err, goecs.ErrEntityNotFound) {
+		t.Fatalf("error on get entity 0 got %v, want %v", err, goecs.ErrEntityNotFound)
+	}
+}
@@ -23,6 +23,7 @@
 package goecs
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -42,9 +43,13 @@ const (
 // World is a view.View that contains the Entity and System of our ECS
 type World struct {
 	*View
-	systems       *Systems       // systems registration of System
-	subscriptions *Subscriptions // subscriptions of Listener to signals
-	resources     *View          // resources of this world
+	systems       *Systems        // systems registration of System
+	subscriptions *Subscriptions  // subscriptions of Listener to signals
+	resources     *View           // resources of this world
+	ctx           context.Context // ctx is the context of the current Update
+	delta         float32         // delta of the current Update
+	scoped        bool            // scoped is true for the World of a System running in parallel
+	since         uint64          // changes after this tick are reported to the System, when scoped
 }
 
 // String get a string representation of our World
@@ -63,38 +68,169 @@ func (world World) String() string {
 	return result
 }
 
-// AddSystem adds the given System to the world
-func (world *World) AddSystem(sys System) {
-	world.AddSystemWithPriority(sys, defaultPriority)
+// AddSystem adds the given System to the world with a varg SystemOption, returning it SystemID
+//
+//	world.AddSystem(RenderSystem, goecs.InStage(goecs.RenderStage))
+func (world *World) AddSystem(sys System, options ...SystemOption) SystemID {
+	return world.AddSystemWithPriority(sys, defaultPriority, options...)
+}
+
+// AddSystemWithPriority adds the given System to the world with a priority and a varg SystemOption, returning it
+// SystemID
+func (world *World) AddSystemWithPriority(sys System, priority int32, options ...SystemOption) SystemID {
+	return world.systems.Register(sys, priority, options...)
+}
+
+// AddStartupSystem adds the given System to the world to run once before the next Update, returning it SystemID
+func (world *World) AddStartupSystem(sys System, options ...SystemOption) SystemID {
+	return world.AddSystem(sys, append(options, InStage(StartupStage))...)
+}
+
+// AddShutdownSystem adds the given System to the world to run once on Close, returning it SystemID
+func (world *World) AddShutdownSystem(sys System, options ...SystemOption) SystemID {
+	return world.AddSystem(sys, append(options, InStage(ShutdownStage))...)
+}
+
+// AddStageBefore adds a new Stage to the world that runs before an existing one
+func (world *World) AddStageBefore(stage, before Stage) error {
+	return world.systems.AddStageBefore(stage, before)
+}
+
+// AddStageAfter adds a new Stage to the world that runs after an existing one
+func (world *World) AddStageAfter(stage, after Stage) error {
+	return world.systems.AddStageAfter(stage, after)
+}
+
+// SetParallel sets the number of workers to run systems in parallel, lower than 2 run the systems sequentially
+//
+// Systems that declare the ComponentType that they read and write, with the Reads and Writes SystemOption, run in
+// parallel with the systems that are next to them in the same Stage if they do not conflict and do not have ordering
+// constraints between them. Each of them get its own World, that reports to World.Filter and World.Iterator the
+// changes since the System last run, while a registered Query reports the changes since the oldest of them. Systems
+// running in parallel should not add or remove entities or components, they could use World.Signal to defer those
+// changes to a Listener.
+func (world *World) SetParallel(workers int) {
+	world.systems.SetParallel(workers)
 }
 
-// AddSystemWithPriority adds the given System to the world with a priority
-func (world *World) AddSystemWithPriority(sys System, priority int32) {
-	world.systems.Register(sys, priority)
+// SetFixedTimestep enables the fixed timestep for the systems in FixedUpdateStage, a step lower or equal to 0
+// disables it
+//
+// On each Update the delta is accumulated and the fixed systems run with the step as delta, as many times as steps
+// are accumulated up to maxSubSteps, the time that could not be run in maxSubSteps is discarded. Without fixed
+// timestep the systems in FixedUpdateStage run once on each Update with the Update delta.
+func (world *World) SetFixedTimestep(step float32, maxSubSteps int) {
+	world.systems.SetFixedTimestep(step, maxSubSteps)
 }
 
-// AddListener adds the given Listener to the world
-func (world *World) AddListener(lis Listener, signals ...ComponentType) {
-	world.AddListenerWithPriority(lis, defaultPriority, signals...)
+// Alpha returns how far we are between the last fixed step and the next one, from 0 to 1, to interpolate the state
+// of the fixed systems in the render systems, is 1 if the fixed timestep is not enabled
+func (world World) Alpha() float32 {
+	return world.systems.Alpha()
 }
 
-// AddListenerWithPriority adds the given Listener to the world with a priority
-func (world *World) AddListenerWithPriority(lis Listener, priority int32, signals ...ComponentType) {
-	world.subscriptions.Subscribe(lis, priority, signals...)
+// Frame returns the number of times that the World has been updated
+func (world World) Frame() uint64 {
+	return world.systems.Frame()
+}
+
+// Stages returns the stages of the world in the order that they run
+func (world World) Stages() []Stage {
+	return world.systems.Stages()
+}
+
+// RemoveSystem removes the System with the given SystemID from the world
+func (world *World) RemoveSystem(id SystemID) error {
+	return world.systems.Remove(id)
+}
+
+// EnableSystem enables the System with the given SystemID, so it will run on Update
+func (world *World) EnableSystem(id SystemID) error {
+	return world.systems.Enable(id)
+}
+
+// DisableSystem disables the System with the given SystemID, so it will not run on Update until is enabled again
+func (world *World) DisableSystem(id SystemID) error {
+	return world.systems.Disable(id)
+}
+
+// SystemStatus returns the SystemStatus of the System with the given SystemID
+func (world World) SystemStatus(id SystemID) (SystemStatus, error) {
+	return world.systems.Status(id)
+}
+
+// AddListener adds the given Listener to the world, returning it Subscription
+func (world *World) AddListener(lis Listener, signals ...ComponentType) *Subscription {
+	return world.AddListenerWithPriority(lis, defaultPriority, signals...)
+}
+
+// AddListenerWithPriority adds the given Listener to the world with a priority, returning it Subscription
+func (world *World) AddListenerWithPriority(lis Listener, priority int32, signals ...ComponentType) *Subscription {
+	return world.subscriptions.Subscribe(lis, priority, signals...)
 }
 
 // Update ask to update the System and send the signals
 func (world *World) Update(delta float32) error {
-	// update the systems
-	if err := world.systems.Update(world, delta); err != nil {
-		return err
+	return world.UpdateContext(context.Background(), delta)
+}
+
+// UpdateContext update the world with a context.Context that is available to the systems and listeners with
+// World.Context
+//
+// The context is checked before each System and signal, if it is done the Update stops returning the context error.
+func (world *World) UpdateContext(ctx context.Context, delta float32) error {
+	world.ctx, world.delta = ctx, delta
+	defer func() {
+		world.ctx, world.delta = context.Background(), 0
+		world.subscriptions.endFrame()
+	}()
+
+	// update the systems, systems in stages not found does not stop the listeners
+	missing, err := world.systems.update(world, delta)
+	if err != nil && (world.systems.policy == FailFast || ctx.Err() != nil) {
+		return joinErrors(missing, err)
 	}
 
 	// update the subscriptions
-	if err := world.subscriptions.Update(world, delta); err != nil {
-		return err
+	return joinErrors(missing, err, world.subscriptions.Update(world, delta))
+}
+
+// Filter return an Iterator for the entities that match the given varg Term, see View.Filter
+func (world *World) Filter(terms ...Term) *Iterator {
+	if world.scoped {
+		return world.View.filter(world.since, terms...)
 	}
-	return nil
+	return world.View.Filter(terms...)
+}
+
+// Iterator return an Iterator for the given varg ComponentType, see View.Iterator
+func (world *World) Iterator(types ...ComponentType) *Iterator {
+	return world.Filter(With(types...))
+}
+
+// Context returns the context.Context of the current Update, context.Background if it is not updating
+func (world World) Context() context.Context {
+	return world.ctx
+}
+
+// SetErrorPolicy sets the ErrorPolicy for the errors of the systems and listeners, by default FailFast
+//
+// With FailFast the first error stops the Update and is returned, with Continue all systems and listeners run and
+// their errors are returned joined. The errors of the systems are wrapped in a *SystemError and the errors of the
+// listeners in a *ListenerError.
+func (world *World) SetErrorPolicy(policy ErrorPolicy) {
+	world.systems.SetErrorPolicy(policy)
+	world.subscriptions.SetErrorPolicy(policy)
+}
+
+// SetRecover sets if the panics of the systems and listeners are converted into a *PanicError, that is handled as
+// any other error of them
+//
+// While enabled the World records the last Entity iterated, that is reported in the *PanicError.
+func (world *World) SetRecover(enabled bool) {
+	world.systems.SetRecover(enabled)
+	world.subscriptions.SetRecover(enabled)
+	world.View.track(enabled)
 }
 
 // Signal to be sent
@@ -102,6 +238,43 @@ func (world *World) Signal(signal interface{}) {
 	world.subscriptions.Signal(signal)
 }
 
+// SetSignalRounds sets the maximum rounds of signals to send on each Update, by default 1
+//
+// With one round the signals sent by the listeners are sent on the next Update, with more rounds they are sent on
+// the same Update until there is no more signals. If there are signals pending after the maximum rounds Update
+// returns an ErrSignalLoop and the signals are kept to the next Update.
+func (world *World) SetSignalRounds(rounds int) {
+	world.subscriptions.SetSignalRounds(rounds)
+}
+
+// Emit sends a signal to the listeners immediately, instead of after the systems as Signal, the listeners get the
+// delta of the current Update
+//
+// Signals emitted by the listeners while a signal is being emitted are sent after it by the same Emit, that returns
+// their errors, with the FailFast policy an error discards the signals not sent yet. Signals emitted by systems
+// running in parallel are sent when all the systems in the batch have finished, so Emit returns before their listeners
+// run and the errors are returned by Update.
+func (world *World) Emit(signal Component) error {
+	return world.subscriptions.Emit(world, signal, world.delta)
+}
+
+// listening check if there is any Listener for the given signal type
+func (world *World) listening(signal ComponentType) bool {
+	return world.subscriptions.listening(signal)
+}
+
+// Close runs the shutdown systems and sends their signals to the listeners, then clears the World
+//
+// The World is cleared even if there is an error
+func (world *World) Close() error {
+	defer world.Clear()
+	err := world.systems.Shutdown(world)
+	if err != nil && world.systems.policy == FailFast {
+		return err
+	}
+	return joinErrors(err, world.subscriptions.Update(world, 0))
+}
+
 // Clear removes all System, Listener, Subscriptions, Entity and Resources from the World
 func (world *World) Clear() {
 	world.systems.Clear()
@@ -118,8 +291,8 @@ func (world *World) AddResource(components ...Component) EntityID {
 	return world.resources.AddEntity(components...)
 }
 
-// GetResource gets a resource from the world
-func (world World) GetResource(id EntityID) *Entity {
+// GetResource gets a resource from the world, return ErrEntityNotFound if the resource has been removed
+func (world World) GetResource(id EntityID) (*Entity, error) {
 	return world.resources.Get(id)
 }
 
@@ -156,11 +329,17 @@ func Default() *World {
 // New creates World with a giving initial capacity of entities, systems, listeners and signals
 //
 // Since those elements are sparse.Slice the will grow dynamically
+//
+// Adding or removing entities and components will send the lifecycle signals: EntityCreated, EntityRemoved,
+// ComponentAdded and ComponentRemoved
 func New(entities, systems, listeners, signals, resources int) *World {
-	return &World{
+	world := &World{
 		View:          NewView(entities),
 		systems:       NewSystems(systems),
 		subscriptions: NewSubscriptions(listeners, signals),
 		resources:     NewView(resources),
+		ctx:           context.Background(),
 	}
+	world.View.lifecycle = world
+	return world
 }
@@ -23,11 +23,13 @@
 package goecs_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/juan-medina/goecs"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var resetSignalEventType = goecs.NewComponentType()
@@ -528,7 +530,13 @@ func Test_Resources(t *testing.T) {
 	}
 
 	want := score{points: 100}
-	got := world.GetResource(idScore).Get(scoreType).(score)
+	res, err := world.GetResource(idScore)
+
+	if err != nil {
+		t.Fatalf("error on get resource got %v, want nil", err)
+	}
+
+	got := res.Get(scoreType).(score)
 
 	if got != want {
 		t.Fatalf("error on get resource got %v, want %v", got, want)
@@ -540,7 +548,7 @@ func Test_Resources(t *testing.T) {
 		t.Fatalf("error on get resource got id %v, want id %v", id, 0)
 	}
 
-	err := world.RemoveResource(idScore)
+	err = world.RemoveResource(idScore)
 
 	if err != nil {
 		t.Fatalf("error on remove resource got  %v, want nil", err)
@@ -562,3 +570,416 @@ func (s score) Type() goecs.ComponentType {
 }
 
 var scoreType = goecs.NewComponentType()
+
+func TestWorld_RemoveSystem(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	idA := world.AddSystem(systemA)
+	world.AddSystem(systemB)
+
+	if err := world.RemoveSystem(idA); err != nil {
+		t.Fatalf("error on remove system got %v, want nil", err)
+	}
+
+	_ = world.Update(0)
+
+	expect := []string{"update b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	if err := world.RemoveSystem(idA); !errors.Is(err, goecs.ErrSystemNotFound) {
+		t.Fatalf("error on remove system got %v, want %v", err, goecs.ErrSystemNotFound)
+	}
+}
+
+func TestWorld_DisableSystem(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	idA := world.AddSystem(systemA)
+	world.AddSystem(systemB)
+
+	if err := world.DisableSystem(idA); err != nil {
+		t.Fatalf("error on disable system got %v, want nil", err)
+	}
+
+	status, err := world.SystemStatus(idA)
+	if err != nil || status.Enabled || status.Name != "github.com/juan-medina/goecs_test.systemA" {
+		t.Fatalf("error on system status got %v, %v", status, err)
+	}
+
+	_ = world.Update(0)
+
+	if err := world.EnableSystem(idA); err != nil {
+		t.Fatalf("error on enable system got %v, want nil", err)
+	}
+
+	if status, _ = world.SystemStatus(idA); !status.Enabled {
+		t.Fatalf("error on system status got %v, want enabled", status)
+	}
+
+	_ = world.Update(0)
+
+	expect := []string{"update b", "update a", "update b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	for _, fn := range []func(goecs.SystemID) error{world.EnableSystem, world.DisableSystem} {
+		if err := fn(goecs.SystemID(100)); !errors.Is(err, goecs.ErrSystemNotFound) {
+			t.Fatalf("error on unknown system got %v, want %v", err, goecs.ErrSystemNotFound)
+		}
+	}
+
+	if _, err := world.SystemStatus(goecs.SystemID(100)); !errors.Is(err, goecs.ErrSystemNotFound) {
+		t.Fatalf("error on unknown system status got %v, want %v", err, goecs.ErrSystemNotFound)
+	}
+}
+
+func TestWorld_SystemOrdering(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	idA := world.AddSystem(systemA)
+	idB := world.AddSystemWithPriority(systemB, 10, goecs.After(idA))
+	world.AddSystemWithPriority(systemC, 20, goecs.After(idA), goecs.Before(idB))
+
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	expect := []string{"update a", "update c", "update b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_SystemOrderingCycle(t *testing.T) {
+	world := goecs.Default()
+
+	idA := world.AddSystem(systemA)
+	idB := world.AddSystem(systemB, goecs.After(idA))
+	_ = world.AddSystem(systemC, goecs.After(idB), goecs.Before(idA))
+
+	if err := world.Update(0); !errors.Is(err, goecs.ErrSystemCycle) {
+		t.Fatalf("error on update got %v, want %v", err, goecs.ErrSystemCycle)
+	}
+
+	world = goecs.Default()
+	idA = world.AddSystem(systemA, goecs.InStage(goecs.RenderStage))
+	_ = world.AddSystem(systemB, goecs.After(idA))
+
+	if err := world.Update(0); !errors.Is(err, goecs.ErrSystemCycle) {
+		t.Fatalf("error on update got %v, want %v", err, goecs.ErrSystemCycle)
+	}
+}
+
+func TestWorld_StartupAndShutdown(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	world.AddSystem(systemA)
+	world.AddStartupSystem(systemB)
+	world.AddShutdownSystem(systemC)
+	world.AddListener(listenerA, dummySignalType)
+	world.AddShutdownSystem(func(world *goecs.World, _ float32) error {
+		world.Signal(dummySignal{})
+		return nil
+	})
+
+	_ = world.Update(0)
+	_ = world.Update(0)
+
+	world.AddStartupSystem(systemB)
+	_ = world.Update(0)
+
+	if err := world.Close(); err != nil {
+		t.Fatalf("error on close got %v, want nil", err)
+	}
+
+	expect := []string{"update b", "update a", "update a", "update b", "update a", "update c", "notify a"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	if world.String() != goecs.Default().String() {
+		t.Fatalf("got %v, want empty world", world)
+	}
+}
+
+func TestWorld_Close_Error(t *testing.T) {
+	world := goecs.Default()
+	world.AddShutdownSystem(FailureSystem)
+	world.AddEntity(Pos{X: 0, Y: 0})
+
+	if err := world.Close(); err == nil {
+		t.Fatal("error on close got nil, want error")
+	}
+
+	if world.Size() != 0 {
+		t.Fatalf("got %d entities, want 0", world.Size())
+	}
+}
+
+func TestWorld_UpdateContext(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+
+	world.AddSystem(func(world *goecs.World, _ float32) error {
+		if world.Context().Value(key{}) != "value" {
+			t.Fatalf("got context value %v, want value", world.Context().Value(key{}))
+		}
+		cancel()
+		return nil
+	})
+	world.AddSystem(systemA)
+	world.AddListener(listenerA, dummySignalType)
+	world.Signal(dummySignal{})
+
+	if err := world.UpdateContext(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error on update got %v, want %v", err, context.Canceled)
+	}
+
+	if len(systemCalls) != 0 {
+		t.Fatalf("got %v, want no calls", systemCalls)
+	}
+
+	if world.Context() != context.Background() {
+		t.Fatalf("got context %v, want background", world.Context())
+	}
+
+	world.SetErrorPolicy(goecs.Continue)
+	world.AddSystem(FailureSystem, goecs.InStage(goecs.PreUpdateStage))
+	ctx, cancel = context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	defer cancel()
+
+	err := world.UpdateContext(ctx, 0)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errFailure) {
+		t.Fatalf("error on update got %v, want %v and %v", err, context.Canceled, errFailure)
+	}
+
+	if len(systemCalls) != 0 {
+		t.Fatalf("got %v, want no calls", systemCalls)
+	}
+}
+
+func TestWorld_Subscription(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	subA := world.AddListener(listenerA, dummySignalType)
+	subB := world.AddListener(listenerB, resetSignalEventType)
+
+	subB.AddSignals(dummySignalType, resetSignalEventType)
+	if want := []goecs.ComponentType{resetSignalEventType, dummySignalType}; !reflect.DeepEqual(subB.Signals(), want) {
+		t.Fatalf("got signals %v, want %v", subB.Signals(), want)
+	}
+
+	world.Signal(dummySignal{})
+	_ = world.Update(0)
+
+	if err := subA.Unsubscribe(); err != nil {
+		t.Fatalf("error on unsubscribe got %v, want nil", err)
+	}
+	world.Signal(dummySignal{})
+	_ = world.Update(0)
+
+	subB.RemoveSignals(dummySignalType)
+	world.Signal(dummySignal{})
+	world.Signal(resetSignalEvent{})
+	_ = world.Update(0)
+
+	expect := []string{"notify a", "notify b", "notify b", "notify b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	if err := subA.Unsubscribe(); !errors.Is(err, goecs.ErrSubscriptionNotFound) {
+		t.Fatalf("error on unsubscribe got %v, want %v", err, goecs.ErrSubscriptionNotFound)
+	}
+
+	world.Clear()
+	if err := subB.Unsubscribe(); !errors.Is(err, goecs.ErrSubscriptionNotFound) {
+		t.Fatalf("error on unsubscribe got %v, want %v", err, goecs.ErrSubscriptionNotFound)
+	}
+}
+
+func TestWorld_SubscriptionIndex(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	var subB *goecs.Subscription
+	world.AddListenerWithPriority(func(world *goecs.World, signal goecs.Component, delta float32) error {
+		_ = subB.Unsubscribe()
+		world.AddListenerWithPriority(listenerA, 10, dummySignalType)
+		return listenerA(world, signal, delta)
+	}, 5, dummySignalType)
+	subB = world.AddListener(listenerB, dummySignalType)
+	world.AddListenerWithPriority(listenerB, 20, resetSignalEventType)
+
+	world.Signal(dummySignal{})
+	_ = world.Update(0)
+
+	expect := []string{"notify a"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	systemCalls = make([]string, 0)
+	world.Signal(resetSignalEvent{})
+	_ = world.Update(0)
+
+	expect = []string{"notify b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_Emit(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	var deltas []float32
+	world.AddListener(func(world *goecs.World, signal goecs.Component, delta float32) error {
+		deltas = append(deltas, delta)
+		// emitted while emitting, it will be sent after this signal
+		if err := world.Emit(resetSignalEvent{}); err != nil {
+			return err
+		}
+		return listenerA(world, signal, delta)
+	}, dummySignalType)
+	world.AddListener(listenerB, resetSignalEventType)
+
+	world.AddSystem(func(world *goecs.World, _ float32) error {
+		if err := world.Emit(dummySignal{}); err != nil {
+			return err
+		}
+		return systemA(world, 0)
+	})
+
+	if err := world.Update(0.5); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	expect := []string{"notify a", "notify b", "update a"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	if want := []float32{0.5}; !reflect.DeepEqual(deltas, want) {
+		t.Fatalf("got deltas %v, want %v", deltas, want)
+	}
+
+	world.AddListener(FailureListener, resetSignalEventType)
+	if err := world.Emit(dummySignal{}); !errors.Is(err, errFailure) {
+		t.Fatalf("error on emit got %v, want %v", err, errFailure)
+	}
+}
+
+func TestWorld_EmitCaptured(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	// a listener that emits on the World that it captures, instead of the one that it gets
+	world.AddListener(func(_ *goecs.World, signal goecs.Component, delta float32) error {
+		if err := world.Emit(resetSignalEvent{}); err != nil {
+			return err
+		}
+		return listenerA(world, signal, delta)
+	}, dummySignalType)
+	world.AddListener(func(got *goecs.World, signal goecs.Component, delta float32) error {
+		if got != world {
+			return errors.New("listener gets a different world")
+		}
+		return listenerB(got, signal, delta)
+	}, resetSignalEventType)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- world.Emit(dummySignal{})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("error on emit got %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("emit is blocked")
+	}
+
+	expect := []string{"notify a", "notify b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_StopPropagation(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+
+	world.AddListenerWithPriority(listenerA, 10, dummySignalType)
+	sub := world.AddListenerWithPriority(func(_ *goecs.World, _ goecs.Component, _ float32) error {
+		return fmt.Errorf("click handled: %w", goecs.ErrStopPropagation)
+	}, 5, dummySignalType)
+	world.AddListener(listenerB, dummySignalType)
+
+	world.Signal(dummySignal{})
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	_ = sub.Unsubscribe()
+	if err := world.Emit(dummySignal{}); err != nil {
+		t.Fatalf("error on emit got %v, want nil", err)
+	}
+
+	expect := []string{"notify a", "notify a", "notify b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+}
+
+func TestWorld_SetSignalRounds(t *testing.T) {
+	systemCalls = make([]string, 0)
+	world := goecs.Default()
+	world.SetSignalRounds(3)
+
+	world.AddListener(func(world *goecs.World, signal goecs.Component, delta float32) error {
+		world.Signal(resetSignalEvent{})
+		return listenerA(world, signal, delta)
+	}, dummySignalType)
+	world.AddListener(listenerB, resetSignalEventType)
+
+	world.Signal(dummySignal{})
+	if err := world.Update(0); err != nil {
+		t.Fatalf("error on update got %v, want nil", err)
+	}
+
+	expect := []string{"notify a", "notify b"}
+	if !reflect.DeepEqual(systemCalls, expect) {
+		t.Fatalf("got %v, want %v", systemCalls, expect)
+	}
+
+	// a listener that always signal again
+	world.AddListener(func(world *goecs.World, _ goecs.Component, _ float32) error {
+		world.Signal(resetSignalEvent{})
+		return nil
+	}, resetSignalEventType)
+
+	world.Signal(resetSignalEvent{})
+	if err := world.Update(0); !errors.Is(err, goecs.ErrSignalLoop) {
+		t.Fatalf("error on update got %v, want %v", err, goecs.ErrSignalLoop)
+	}
+
+	// pending signals are kept to the next update
+	err := world.Update(0)
+	if want := "signal loop: 1 signals pending after 3 rounds"; err == nil || err.Error() != want {
+		t.Fatalf("error on update got %v, want %v", err, want)
+	}
+}